@@ -0,0 +1,249 @@
+package gontainer
+
+import (
+	"context"
+	"sync"
+)
+
+// Result is the outcome of one item of a batch operation. "Val" is the value
+// which was got or deleted, and is the zero value for puts and failures.
+type Result[K comparable, V any] struct {
+	Key K
+	Val V
+	Err error
+}
+
+// -----------------------------------------------------------------------------
+// BatchPutter.
+// -----------------------------------------------------------------------------
+
+// BatchPutter represents something which stores many values at once. The
+// results are in the same order as "entries", and report failures per key
+// (i.e. partial failure). The error is for failures of the batch as a whole,
+// in which case the results may be nil.
+//
+// If the ctx is done before the batch starts, it fails as a whole with an
+// *OpError which wraps the ctx error, and the results are nil. If the ctx is
+// done while the batch runs, the items which have not been started yet fail
+// with an *OpError which wraps the ctx error, and the error is nil. The same
+// goes for BatchGetter and BatchDeleter.
+type BatchPutter[K comparable, V any] interface {
+	PutMany(ctx context.Context, entries []Entry[K, V]) (results []Result[K, V], err error)
+}
+
+// BatchPutterImpl lets you implement BatchPutter with a function. The call to
+// PutMany is simply forwarded to the internal function "Impl".
+type BatchPutterImpl[K comparable, V any] struct {
+	Impl func(ctx context.Context, entries []Entry[K, V]) (results []Result[K, V], err error)
+}
+
+// PutMany implements BatchPutter.PutMany by forwarding the call to the internal
+// "Impl".
+func (impl BatchPutterImpl[K, V]) PutMany(
+	ctx context.Context,
+	entries []Entry[K, V],
+) (
+	results []Result[K, V],
+	err error,
+) {
+	if impl.Impl == nil {
+		err = ErrImpl
+		return
+	}
+
+	return impl.Impl(ctx, entries)
+}
+
+// -----------------------------------------------------------------------------
+// BatchGetter.
+// -----------------------------------------------------------------------------
+
+// BatchGetter represents something which gets many stored values at once. See
+// BatchPutter for the meaning of the results and the error. Missing keys are
+// reported per key, with an error which wraps ErrNotFound.
+type BatchGetter[K comparable, V any] interface {
+	GetMany(ctx context.Context, keys []K) (results []Result[K, V], err error)
+}
+
+// BatchGetterImpl lets you implement BatchGetter with a function. The call to
+// GetMany is simply forwarded to the internal function "Impl".
+type BatchGetterImpl[K comparable, V any] struct {
+	Impl func(ctx context.Context, keys []K) (results []Result[K, V], err error)
+}
+
+// GetMany implements BatchGetter.GetMany by forwarding the call to the internal
+// "Impl".
+func (impl BatchGetterImpl[K, V]) GetMany(
+	ctx context.Context,
+	keys []K,
+) (
+	results []Result[K, V],
+	err error,
+) {
+	if impl.Impl == nil {
+		err = ErrImpl
+		return
+	}
+
+	return impl.Impl(ctx, keys)
+}
+
+// -----------------------------------------------------------------------------
+// BatchDeleter.
+// -----------------------------------------------------------------------------
+
+// BatchDeleter represents something which deletes many stored values at once.
+// See BatchGetter for the meaning of the results and the error.
+type BatchDeleter[K comparable, V any] interface {
+	DelMany(ctx context.Context, keys []K) (results []Result[K, V], err error)
+}
+
+// BatchDeleterImpl lets you implement BatchDeleter with a function. The call to
+// DelMany is simply forwarded to the internal function "Impl".
+type BatchDeleterImpl[K comparable, V any] struct {
+	Impl func(ctx context.Context, keys []K) (results []Result[K, V], err error)
+}
+
+// DelMany implements BatchDeleter.DelMany by forwarding the call to the internal
+// "Impl".
+func (impl BatchDeleterImpl[K, V]) DelMany(
+	ctx context.Context,
+	keys []K,
+) (
+	results []Result[K, V],
+	err error,
+) {
+	if impl.Impl == nil {
+		err = ErrImpl
+		return
+	}
+
+	return impl.Impl(ctx, keys)
+}
+
+// -----------------------------------------------------------------------------
+// Batcher.
+// -----------------------------------------------------------------------------
+
+// Batcher groups BatchPutter, BatchGetter and BatchDeleter.
+type Batcher[K comparable, V any] interface {
+	BatchPutter[K, V]
+	BatchGetter[K, V]
+	BatchDeleter[K, V]
+}
+
+// BatcherImpl lets you implement Batcher with functions. It groups
+// BatchPutterImpl, BatchGetterImpl and BatchDeleterImpl.
+type BatcherImpl[K comparable, V any] struct {
+	BatchPutterImpl[K, V]
+	BatchGetterImpl[K, V]
+	BatchDeleterImpl[K, V]
+}
+
+// Batch returns a Batcher for "c". If "c" implements Batcher itself (as New
+// does), it is returned as is. Otherwise, the batch operations fall back to
+// calling Put, Get and Del of "c" per item, with at most "parallelism" calls
+// at once. The ctx is handled as explained for BatchPutter.
+//
+// Notes:
+//   - "parallelism" is clamped to 1 if it is smaller.
+//   - The fallback is not atomic, items of a batch may interleave with other
+//     calls to "c".
+func Batch[K comparable, V any](c Container[K, V], parallelism int) Batcher[K, V] {
+	if b, ok := c.(Batcher[K, V]); ok {
+		return b
+	}
+
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	b := BatcherImpl[K, V]{}
+	b.BatchPutterImpl.Impl = func(
+		ctx context.Context,
+		entries []Entry[K, V],
+	) (
+		results []Result[K, V],
+		err error,
+	) {
+		if err = ctx.Err(); err != nil {
+			err = &OpError{Op: OpPut, Err: err}
+			return
+		}
+
+		results = make([]Result[K, V], len(entries))
+		fanOut(parallelism, len(entries), func(i int) {
+			results[i].Key = entries[i].Key
+			results[i].Err = ctxOpError(ctx, OpPut, entries[i].Key, func() error {
+				return c.Put(ctx, entries[i].Key, entries[i].Val)
+			})
+		})
+
+		return
+	}
+
+	b.BatchGetterImpl.Impl = func(ctx context.Context, keys []K) (results []Result[K, V], err error) {
+		if err = ctx.Err(); err != nil {
+			err = &OpError{Op: OpGet, Err: err}
+			return
+		}
+
+		results = make([]Result[K, V], len(keys))
+		fanOut(parallelism, len(keys), func(i int) {
+			results[i].Key = keys[i]
+			results[i].Err = ctxOpError(ctx, OpGet, keys[i], func() (err error) {
+				results[i].Val, err = c.Get(ctx, keys[i])
+				return
+			})
+		})
+
+		return
+	}
+
+	b.BatchDeleterImpl.Impl = func(ctx context.Context, keys []K) (results []Result[K, V], err error) {
+		if err = ctx.Err(); err != nil {
+			err = &OpError{Op: OpDel, Err: err}
+			return
+		}
+
+		results = make([]Result[K, V], len(keys))
+		fanOut(parallelism, len(keys), func(i int) {
+			results[i].Key = keys[i]
+			results[i].Err = ctxOpError(ctx, OpDel, keys[i], func() (err error) {
+				results[i].Val, err = c.Del(ctx, keys[i])
+				return
+			})
+		})
+
+		return
+	}
+
+	return b
+}
+
+// fanOut calls "f" for 0 to n-1, with at most "parallelism" calls at once. It
+// returns when all calls have returned.
+func fanOut(parallelism int, n int, f func(i int)) {
+	sem := make(chan struct{}, parallelism)
+	wg := sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			f(i)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// ctxOpError returns an OpError which wraps the ctx error if the ctx is done,
+// and calls "f" otherwise.
+func ctxOpError(ctx context.Context, op Op, key any, f func() error) error {
+	if err := ctx.Err(); err != nil {
+		return &OpError{Op: op, Key: key, Err: err}
+	}
+
+	return f()
+}
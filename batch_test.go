@@ -0,0 +1,181 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Tests for the Batch Impls.
+// -----------------------------------------------------------------------------
+
+func TestBatcherImplWithNil(t *testing.T) {
+	b := BatcherImpl[int, int]{}
+	ctx := context.Background()
+
+	_, err := b.PutMany(ctx, nil)
+	assertEq("put", true, errors.Is(err, ErrImpl), func(s string) { t.Fatal(s) })
+
+	_, err = b.GetMany(ctx, nil)
+	assertEq("get", true, errors.Is(err, ErrImpl), func(s string) { t.Fatal(s) })
+
+	_, err = b.DelMany(ctx, nil)
+	assertEq("del", true, errors.Is(err, ErrImpl), func(s string) { t.Fatal(s) })
+}
+
+// -----------------------------------------------------------------------------
+// Tests for Batch.
+// -----------------------------------------------------------------------------
+
+// testBatcher checks the semantics shared by native and fallback Batchers.
+func testBatcher(t *testing.T, name string, cnt Container[int, int], b Batcher[int, int]) {
+	ctx := context.Background()
+	fatal := func(s string) { t.Fatal(name + ": " + s) }
+
+	results, err := b.PutMany(ctx, []Entry[int, int]{{1, 10}, {2, 20}, {3, 30}})
+	assertEq("put err", *new(error), err, fatal)
+	assertEq("put results", 3, len(results), fatal)
+	for i, r := range results {
+		assertEq("put key", i+1, r.Key, fatal)
+		assertEq("put result err", *new(error), r.Err, fatal)
+	}
+
+	n, _ := cnt.Len(ctx)
+	assertEq("len", 3, n, fatal)
+
+	// Partial failure, key 4 is missing.
+	results, err = b.GetMany(ctx, []int{3, 4, 1})
+	assertEq("get err", *new(error), err, fatal)
+	assertEq("get val 0", 30, results[0].Val, fatal)
+	assertEq("get val 2", 10, results[2].Val, fatal)
+	assertEq("get missing", true, errors.Is(results[1].Err, ErrNotFound), fatal)
+	assertEq("get missing op", true, errors.Is(results[1].Err, ErrGet), fatal)
+	assertEq("get ok", *new(error), results[0].Err, fatal)
+
+	results, err = b.DelMany(ctx, []int{2, 5})
+	assertEq("del err", *new(error), err, fatal)
+	assertEq("del val", 20, results[0].Val, fatal)
+	assertEq("del missing", true, errors.Is(results[1].Err, ErrNotFound), fatal)
+
+	n, _ = cnt.Len(ctx)
+	assertEq("len after del", 2, n, fatal)
+}
+
+func TestBatchNative(t *testing.T) {
+	cnt := New[int, int]()
+	b := Batch(cnt, 4)
+
+	_, native := b.(*mapWrap[int, int])
+	assertEq("native", true, native, func(s string) { t.Fatal(s) })
+
+	testBatcher(t, "native", cnt, b)
+}
+
+func TestBatchFallback(t *testing.T) {
+	cnt := NewSharded[int, int](4, nil)
+	testBatcher(t, "fallback", cnt, Batch(cnt, 2))
+}
+
+func TestBatchFallbackParallelism(t *testing.T) {
+	mu := sync.Mutex{}
+	running, peak := 0, 0
+	release := make(chan struct{})
+
+	c := ContainerImpl[int, int]{}
+	c.PutterImpl.Impl = func(context.Context, int, int) error {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+
+		<-release
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	}
+
+	entries := make([]Entry[int, int], 10)
+	done := make(chan struct{})
+	go func() {
+		Batch[int, int](c, 3).PutMany(context.Background(), entries)
+		close(done)
+	}()
+
+	// Wait for the first calls to fill all slots, then let all calls finish.
+	for {
+		mu.Lock()
+		full := running == 3
+		mu.Unlock()
+
+		if full {
+			break
+		}
+
+		runtime.Gosched()
+	}
+
+	for i := 0; i < len(entries); i++ {
+		release <- struct{}{}
+	}
+
+	<-done
+	assertEq("peak", 3, peak, func(s string) { t.Fatal(s) })
+}
+
+func TestBatchCanceled(t *testing.T) {
+	calls := 0
+	fallback := ContainerImpl[int, int]{}
+	fallback.PutterImpl.Impl = func(context.Context, int, int) error { calls++; return nil }
+	fallback.GetterImpl.Impl = func(context.Context, int) (int, error) { calls++; return 0, nil }
+	fallback.DeleterImpl.Impl = func(context.Context, int) (int, error) { calls++; return 0, nil }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for name, b := range map[string]Batcher[int, int]{
+		"native":   Batch(New[int, int](), 1),
+		"fallback": Batch[int, int](fallback, 1),
+	} {
+		fatal := func(s string) { t.Fatal(name + ": " + s) }
+
+		// Both fail as a whole, without results.
+		results, err := b.PutMany(ctx, []Entry[int, int]{{1, 1}})
+		assertEq("put err", true, errors.Is(err, context.Canceled), fatal)
+		assertEq("put op", true, errors.Is(err, ErrPut), fatal)
+		assertEq("put results", 0, len(results), fatal)
+
+		results, err = b.GetMany(ctx, []int{1, 2})
+		assertEq("get err", true, errors.Is(err, context.Canceled), fatal)
+		assertEq("get op", true, errors.Is(err, ErrGet), fatal)
+		assertEq("get results", 0, len(results), fatal)
+
+		results, err = b.DelMany(ctx, []int{1, 2})
+		assertEq("del err", true, errors.Is(err, context.Canceled), fatal)
+		assertEq("del op", true, errors.Is(err, ErrDel), fatal)
+		assertEq("del results", 0, len(results), fatal)
+	}
+
+	assertEq("calls", 0, calls, func(s string) { t.Fatal(s) })
+}
+
+func TestBatchFallbackCanceledWhileRunning(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	c := ContainerImpl[int, int]{}
+	c.GetterImpl.Impl = func(context.Context, int) (int, error) { calls++; cancel(); return 1, nil }
+
+	results, err := Batch[int, int](c, 1).GetMany(ctx, []int{1, 2})
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("calls", 1, calls, func(s string) { t.Fatal(s) })
+	assertEq("first err", *new(error), results[0].Err, func(s string) { t.Fatal(s) })
+	assertEq("first val", 1, results[0].Val, func(s string) { t.Fatal(s) })
+	assertEq("second err", true, errors.Is(results[1].Err, context.Canceled), func(s string) { t.Fatal(s) })
+	assertEq("second op", true, errors.Is(results[1].Err, ErrGet), func(s string) { t.Fatal(s) })
+}
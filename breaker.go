@@ -0,0 +1,186 @@
+package gontainer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a circuit breaker, see BreakerDecorator.
+type BreakerState int
+
+const (
+	// BreakerClosed lets all calls through, while counting failures.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails all calls fast with ErrOpen, until the cool-down.
+	BreakerOpen
+	// BreakerHalfOpen lets a single trial call through, which decides if
+	// the breaker closes again or goes back to open.
+	BreakerHalfOpen
+)
+
+// String implements fmt.Stringer.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+
+	return "unknown"
+}
+
+// BreakerConfig is used to configure BreakerDecorator.
+type BreakerConfig struct {
+	// Threshold is the number of consecutive failures which opens the
+	// breaker. Defaults to 5 if zero or negative.
+	Threshold int
+	// Cooldown is how long the breaker stays open before it lets a trial
+	// call through. Defaults to 10s if zero or negative.
+	Cooldown time.Duration
+	// IsFailure decides which errors count as failures, other errors count
+	// as successes. Defaults to the same classifier as RetryConfig.Retryable,
+	// so that e.g. a missing key does not count, while a timeout does. It is
+	// not called for calls which return after the ctx of the caller is done,
+	// those count as neither.
+	IsFailure func(err error) bool
+	// OnStateChange is called on each state transition, if not nil. It is
+	// called after the breaker is unlocked, so it may call back into it.
+	OnStateChange func(from, to BreakerState)
+	// Clock is the source of time. Defaults to the wall clock if nil.
+	Clock Clock
+}
+
+// breaker is the state shared by all calls through a BreakerDecorator.
+type breaker struct {
+	mu       sync.Mutex
+	cfg      BreakerConfig
+	clock    Clock
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// setState transitions to "to", and returns a func which notifies the
+// transition. Must be called while locked.
+func (b *breaker) setState(to BreakerState) (notify func()) {
+	from := b.state
+	b.state = to
+
+	switch to {
+	case BreakerClosed:
+		b.failures = 0
+	case BreakerOpen:
+		b.openedAt = b.clock.Now()
+	}
+
+	if b.cfg.OnStateChange == nil || from == to {
+		return func() {}
+	}
+
+	return func() { b.cfg.OnStateChange(from, to) }
+}
+
+// allow reports whether a call may go through, and if it is a trial call.
+func (b *breaker) allow() (ok bool, trial bool) {
+	b.mu.Lock()
+
+	notify := func() {}
+	if b.state == BreakerOpen && !b.clock.Now().Before(b.openedAt.Add(b.cfg.Cooldown)) {
+		notify = b.setState(BreakerHalfOpen)
+	}
+
+	switch {
+	case b.state == BreakerClosed:
+		ok = true
+	case b.state == BreakerHalfOpen && !b.trial:
+		b.trial = true
+		ok, trial = true, true
+	}
+
+	b.mu.Unlock()
+
+	notify()
+	return
+}
+
+// done records the outcome of a call which was allowed. A call which returns
+// after the caller's ctx is done says nothing about the store, so it neither
+// counts, nor decides a trial; the next call is then a trial instead.
+func (b *breaker) done(ctx context.Context, trial bool, err error) {
+	b.mu.Lock()
+
+	notify := func() {}
+	canceled := ctx.Err() != nil
+	failed := !canceled && b.cfg.IsFailure(err)
+
+	switch {
+	case canceled:
+		if trial {
+			b.trial = false
+		}
+	case trial:
+		b.trial = false
+		if failed {
+			notify = b.setState(BreakerOpen)
+		} else {
+			notify = b.setState(BreakerClosed)
+		}
+	case b.state != BreakerClosed:
+		// A call which started before the breaker opened, ignore.
+	case !failed:
+		b.failures = 0
+	default:
+		if b.failures++; b.failures >= b.cfg.Threshold {
+			notify = b.setState(BreakerOpen)
+		}
+	}
+
+	b.mu.Unlock()
+
+	notify()
+}
+
+// BreakerDecorator returns a Decorator which acts as a circuit breaker. The
+// breaker opens after BreakerConfig.Threshold consecutive failures, after
+// which calls fail fast with ErrOpen. After BreakerConfig.Cooldown, a single
+// trial call is let through (half-open); if it succeeds the breaker closes,
+// otherwise it opens again.
+//
+// The state is shared by all calls through the returned Decorator, so apply
+// the same Decorator to all interfaces which are backed by the same store.
+func BreakerDecorator(cfg BreakerConfig) Decorator {
+	if cfg.Threshold < 1 {
+		cfg.Threshold = 5
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 10 * time.Second
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = transient
+	}
+
+	b := &breaker{cfg: cfg, clock: clockOr(cfg.Clock)}
+
+	return func(
+		ctx context.Context,
+		op Op,
+		key any,
+		call func(ctx context.Context) error,
+	) (
+		err error,
+	) {
+		ok, trial := b.allow()
+		if !ok {
+			return ErrOpen
+		}
+
+		err = call(ctx)
+		b.done(ctx, trial, err)
+		return
+	}
+}
@@ -0,0 +1,158 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Tests for BreakerDecorator.
+// -----------------------------------------------------------------------------
+
+func TestBreakerDecoratorOpens(t *testing.T) {
+	c, calls := failingContainer(10, errFlaky)
+	clock := newFakeClock()
+
+	transitions := []string{}
+	cfg := BreakerConfig{Threshold: 3, Cooldown: time.Second, Clock: clock}
+	cfg.OnStateChange = func(from, to BreakerState) {
+		transitions = append(transitions, from.String()+">"+to.String())
+	}
+
+	cnt := DecorateContainer[int, int](c, BreakerDecorator(cfg))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		err := cnt.Put(ctx, 1, 1)
+		assertEq("err", true, errors.Is(err, errFlaky), func(s string) { t.Fatal(s) })
+	}
+
+	// Open, fails fast without calling through.
+	_, err := cnt.Get(ctx, 1)
+	assertEq("err", true, errors.Is(err, ErrOpen), func(s string) { t.Fatal(s) })
+	assertEq("calls", 3, *calls, func(s string) { t.Fatal(s) })
+	assertEq("transitions", []string{"closed>open"}, transitions, func(s string) { t.Fatal(s) })
+}
+
+func TestBreakerDecoratorHalfOpen(t *testing.T) {
+	c, calls := failingContainer(4, errFlaky)
+	clock := newFakeClock()
+
+	transitions := []string{}
+	cfg := BreakerConfig{Threshold: 3, Cooldown: time.Second, Clock: clock}
+	cfg.OnStateChange = func(from, to BreakerState) {
+		transitions = append(transitions, from.String()+">"+to.String())
+	}
+
+	cnt := DecorateContainer[int, int](c, BreakerDecorator(cfg))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		cnt.Put(ctx, 1, 1)
+	}
+
+	// Trial call fails, so the breaker opens again.
+	clock.Advance(time.Second)
+	err := cnt.Put(ctx, 1, 1)
+	assertEq("err", true, errors.Is(err, errFlaky), func(s string) { t.Fatal(s) })
+
+	err = cnt.Put(ctx, 1, 1)
+	assertEq("err", true, errors.Is(err, ErrOpen), func(s string) { t.Fatal(s) })
+
+	// Trial call succeeds, so the breaker closes.
+	clock.Advance(time.Second)
+	err = cnt.Put(ctx, 1, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	val, err := cnt.Get(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 1, val, func(s string) { t.Fatal(s) })
+	assertEq("calls", 6, *calls, func(s string) { t.Fatal(s) })
+
+	want := []string{
+		"closed>open",
+		"open>half-open",
+		"half-open>open",
+		"open>half-open",
+		"half-open>closed",
+	}
+	assertEq("transitions", want, transitions, func(s string) { t.Fatal(s) })
+}
+
+func TestBreakerDecoratorIgnoresCallerErrors(t *testing.T) {
+	cfg := BreakerConfig{Threshold: 1, Clock: newFakeClock()}
+	cnt := DecorateContainer(New[int, int](), BreakerDecorator(cfg))
+	ctx := context.Background()
+
+	// Missing keys are not failures of the store.
+	for i := 0; i < 3; i++ {
+		_, err := cnt.Get(ctx, 1)
+		assertEq("err", true, errors.Is(err, ErrNotFound), func(s string) { t.Fatal(s) })
+	}
+}
+
+func TestBreakerDecoratorSearcher(t *testing.T) {
+	calls := 0
+	s := SearcherImpl[int, int]{}
+	s.Impl = func(context.Context, int) (int, error) { calls++; return 0, errFlaky }
+
+	cfg := BreakerConfig{Threshold: 2, Clock: newFakeClock()}
+	ds := DecorateSearcher[int, int](s, BreakerDecorator(cfg))
+
+	for i := 0; i < 3; i++ {
+		ds.Search(context.Background(), 0)
+	}
+
+	_, err := ds.Search(context.Background(), 0)
+	assertEq("err", true, errors.Is(err, ErrOpen), func(s string) { t.Fatal(s) })
+	assertEq("calls", 2, calls, func(s string) { t.Fatal(s) })
+}
+
+func TestBreakerDecoratorTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	g := GetterImpl[int, int]{}
+	g.Impl = func(context.Context, int) (int, error) { <-release; return 0, nil }
+
+	cfg := BreakerConfig{Threshold: 2, Clock: newFakeClock()}
+	d := Chain(BreakerDecorator(cfg), TimeoutDecorator(TimeoutConfig{Default: time.Millisecond}))
+	dg := DecorateGetter[int, int](g, d)
+
+	for i := 0; i < 2; i++ {
+		_, err := dg.Get(context.Background(), 1)
+		assertEq("err", true, errors.Is(err, context.DeadlineExceeded), func(s string) { t.Fatal(s) })
+	}
+
+	_, err := dg.Get(context.Background(), 1)
+	assertEq("open", true, errors.Is(err, ErrOpen), func(s string) { t.Fatal(s) })
+}
+
+func TestBreakerDecoratorCanceledTrial(t *testing.T) {
+	c, _ := failingContainer(1, errFlaky)
+	clock := newFakeClock()
+
+	transitions := []string{}
+	cfg := BreakerConfig{Threshold: 1, Cooldown: time.Second, Clock: clock}
+	cfg.OnStateChange = func(from, to BreakerState) {
+		transitions = append(transitions, from.String()+">"+to.String())
+	}
+
+	cnt := DecorateContainer[int, int](c, BreakerDecorator(cfg))
+	cnt.Put(context.Background(), 1, 1)
+
+	// The trial is canceled by the caller, so it decides nothing.
+	clock.Advance(time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := cnt.Put(ctx, 1, 1)
+	assertEq("canceled", true, errors.Is(err, context.Canceled), func(s string) { t.Fatal(s) })
+	assertEq("transitions", []string{"closed>open", "open>half-open"}, transitions, func(s string) { t.Fatal(s) })
+
+	// The next call is the trial.
+	err = cnt.Put(context.Background(), 1, 1)
+	assertEq("trial", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("closed", "half-open>closed", transitions[len(transitions)-1], func(s string) { t.Fatal(s) })
+}
@@ -0,0 +1,282 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// WritePolicy decides how writes are handled by a container from NewCache.
+type WritePolicy int
+
+const (
+	// WriteThrough writes to the backing container first, then updates the
+	// cache. Mod and Del invalidate the key in the cache.
+	WriteThrough WritePolicy = iota
+	// WriteBack writes only to the cache and marks the key as dirty. Dirty
+	// keys are written to the backing container when flushed.
+	WriteBack
+	// WriteAround writes only to the backing container and invalidates the
+	// key in the cache, so it is populated on the next Get.
+	WriteAround
+)
+
+// cacheWrap composes a fast cache container with a slower backing container.
+// Calls are serialized per key, so that a Get which populates the cache can
+// not interleave with a write which invalidates it. The dirty set has its own
+// lock, which is never held while calling into the containers.
+type cacheWrap[K comparable, V any] struct {
+	locks   keyLocks[K]
+	mu      sync.Mutex
+	cache   Container[K, V]
+	backing Container[K, V]
+	policy  WritePolicy
+	dirty   map[K]struct{}
+}
+
+// NewCache returns a container which reads through "cache" to "backing":
+// a Get which misses the cache reads from "backing" and populates the cache.
+// Put, Mod and Del are handled according to "policy", see WritePolicy. Len
+// and Cap are forwarded to "backing"; with WriteBack, they add the dirty keys
+// which "backing" does not have yet (which costs a Get of "backing" each).
+//
+// The "flush" func writes all dirty keys to "backing", and is only needed
+// with WriteBack (it is a no-op otherwise). It keeps going on failure, and
+// returns all errors joined together; keys which failed stay dirty.
+//
+// Notes:
+//   - With WriteBack, "cache" should not evict on its own (like NewLRU),
+//     since an evicted dirty entry will be lost.
+//   - With WriteBack, Del is not deferred, it deletes from both containers.
+func NewCache[K comparable, V any](
+	cache Container[K, V],
+	backing Container[K, V],
+	policy WritePolicy,
+) (
+	cnt Container[K, V],
+	flush func(ctx context.Context) error,
+) {
+	c := &cacheWrap[K, V]{
+		cache:   cache,
+		backing: backing,
+		policy:  policy,
+		dirty:   make(map[K]struct{}),
+	}
+
+	return c, c.flush
+}
+
+func (c *cacheWrap[K, V]) flush(ctx context.Context) (err error) {
+	errs := []error{}
+	for _, k := range c.dirtyKeys() {
+		if err := c.flushKey(ctx, k); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// flushKey writes "k" to the backing container, if it is still dirty.
+func (c *cacheWrap[K, V]) flushKey(ctx context.Context, k K) (err error) {
+	defer c.locks.lock(k)()
+
+	if !c.isDirty(k) {
+		return
+	}
+
+	v, err := c.cache.Get(ctx, k)
+	if err != nil {
+		return
+	}
+
+	if err = c.backing.Put(ctx, k, v); err != nil {
+		return
+	}
+
+	c.setDirty(k, false)
+	return
+}
+
+// dirtyKeys returns a snapshot of the dirty keys.
+func (c *cacheWrap[K, V]) dirtyKeys() (keys []K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k := range c.dirty {
+		keys = append(keys, k)
+	}
+
+	return
+}
+
+// isDirty reports whether "k" is dirty.
+func (c *cacheWrap[K, V]) isDirty(k K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.dirty[k]
+	return ok
+}
+
+// setDirty adds "k" to or removes it from the dirty keys.
+func (c *cacheWrap[K, V]) setDirty(k K, dirty bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if dirty {
+		c.dirty[k] = struct{}{}
+	} else {
+		delete(c.dirty, k)
+	}
+}
+
+// Put implements Putter.
+func (c *cacheWrap[K, V]) Put(ctx context.Context, k K, v V) (err error) {
+	defer c.locks.lock(k)()
+
+	switch c.policy {
+	case WriteBack:
+		err = c.cache.Put(ctx, k, v)
+		if err == nil {
+			c.setDirty(k, true)
+		}
+	case WriteAround:
+		err = c.backing.Put(ctx, k, v)
+		c.cache.Del(ctx, k)
+	default:
+		err = c.backing.Put(ctx, k, v)
+		if err != nil || c.cache.Put(ctx, k, v) != nil {
+			c.cache.Del(ctx, k)
+		}
+	}
+
+	return
+}
+
+// Get implements Getter.
+func (c *cacheWrap[K, V]) Get(ctx context.Context, k K) (v V, err error) {
+	defer c.locks.lock(k)()
+
+	return c.get(ctx, k)
+}
+
+// get reads through the cache. Must be called while "k" is locked.
+func (c *cacheWrap[K, V]) get(ctx context.Context, k K) (v V, err error) {
+	v, err = c.cache.Get(ctx, k)
+	if err == nil {
+		return
+	}
+
+	v, err = c.backing.Get(ctx, k)
+	if err != nil {
+		return
+	}
+
+	// A failure to populate the cache is not a failure to get.
+	c.cache.Put(ctx, k, v)
+	return
+}
+
+// Mod implements Modifier. Except for WriteBack, the callback runs against the
+// backing container, and the key is then invalidated in the cache.
+func (c *cacheWrap[K, V]) Mod(ctx context.Context, k K, f func(V) V) (err error) {
+	defer c.locks.lock(k)()
+
+	if c.policy != WriteBack {
+		err = c.backing.Mod(ctx, k, f)
+		c.cache.Del(ctx, k)
+		return
+	}
+
+	// Make sure that the cache holds the latest value before modifying it. If
+	// it can not be read, modifying the zero value would overwrite it on flush.
+	if _, err = c.get(ctx, k); err != nil && !errors.Is(err, ErrNotFound) {
+		return reOpError(OpMod, k, err)
+	}
+
+	err = c.cache.Mod(ctx, k, f)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		c.setDirty(k, true)
+	}
+
+	return
+}
+
+// Del implements Deleter. The key is deleted from both containers.
+func (c *cacheWrap[K, V]) Del(ctx context.Context, k K) (v V, err error) {
+	defer c.locks.lock(k)()
+
+	cv, cerr := c.cache.Del(ctx, k)
+	v, err = c.backing.Del(ctx, k)
+	if !c.isDirty(k) || cerr != nil {
+		return
+	}
+
+	// A dirty value is newer than what the backing container had, and it
+	// existed even if the backing container did not have the key. If the
+	// backing container failed, the value is put back so it is not lost.
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		c.cache.Put(ctx, k, cv)
+		return
+	}
+
+	c.setDirty(k, false)
+	return cv, nil
+}
+
+// unflushed returns the number of dirty keys which the backing container does
+// not have yet.
+func (c *cacheWrap[K, V]) unflushed(ctx context.Context) (n int, err error) {
+	for _, k := range c.dirtyKeys() {
+		missing, err := c.missing(ctx, k)
+		if err != nil {
+			return 0, err
+		}
+
+		if missing {
+			n++
+		}
+	}
+
+	return
+}
+
+// missing reports whether "k" is dirty, but not in the backing container.
+func (c *cacheWrap[K, V]) missing(ctx context.Context, k K) (missing bool, err error) {
+	defer c.locks.lock(k)()
+
+	if !c.isDirty(k) {
+		return
+	}
+
+	_, err = c.backing.Get(ctx, k)
+	if errors.Is(err, ErrNotFound) {
+		return true, nil
+	}
+
+	return
+}
+
+// Len implements Container.Len by forwarding to the backing container. With
+// WriteBack, dirty keys which the backing container does not have yet are
+// added to the count.
+func (c *cacheWrap[K, V]) Len(ctx context.Context) (n int, err error) {
+	if n, err = c.backing.Len(ctx); err != nil || c.policy != WriteBack {
+		return
+	}
+
+	m, err := c.unflushed(ctx)
+	return n + m, err
+}
+
+// Cap implements Container.Cap by forwarding to the backing container. With
+// WriteBack, it is increased like Len.
+func (c *cacheWrap[K, V]) Cap(ctx context.Context) (n int, err error) {
+	if n, err = c.backing.Cap(ctx); err != nil || c.policy != WriteBack {
+		return
+	}
+
+	m, err := c.unflushed(ctx)
+	return n + m, err
+}
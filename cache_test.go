@@ -0,0 +1,287 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingContainer returns a ContainerImpl which forwards to "c", except that
+// Get and Del of "key" block until "release" is closed. "blocked" is closed
+// when the first such call blocks.
+func blockingContainer(
+	c Container[int, int],
+	key int,
+	release chan struct{},
+) (
+	cnt ContainerImpl[int, int],
+	blocked chan struct{},
+) {
+	blocked = make(chan struct{})
+	once := sync.Once{}
+	block := func(k int) {
+		if k == key {
+			once.Do(func() { close(blocked) })
+			<-release
+		}
+	}
+
+	cnt = ContainerImpl[int, int]{
+		PutterImpl:   PutterImpl[int, int]{Impl: c.Put},
+		ModifierImpl: ModifierImpl[int, int]{Impl: c.Mod},
+		ImplLen:      c.Len,
+		ImplCap:      c.Cap,
+	}
+	cnt.GetterImpl.Impl = func(ctx context.Context, k int) (int, error) {
+		block(k)
+		return c.Get(ctx, k)
+	}
+	cnt.DeleterImpl.Impl = func(ctx context.Context, k int) (int, error) {
+		block(k)
+		return c.Del(ctx, k)
+	}
+
+	return
+}
+
+// within fails the test unless "f" returns within a second.
+func within(t *testing.T, f func()) {
+	done := make(chan struct{})
+	go func() { f(); close(done) }()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("blocked")
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Tests for NewCache.
+// -----------------------------------------------------------------------------
+
+func TestNewCacheGetPopulates(t *testing.T) {
+	cache, backing := New[int, int](), New[int, int]()
+	cnt, _ := NewCache(cache, backing, WriteThrough)
+	ctx := context.Background()
+
+	backing.Put(ctx, 1, 1)
+
+	val, err := cnt.Get(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 1, val, func(s string) { t.Fatal(s) })
+
+	val, err = cache.Get(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 1, val, func(s string) { t.Fatal(s) })
+
+	_, err = cnt.Get(ctx, 2)
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+}
+
+func TestNewCacheWriteThrough(t *testing.T) {
+	cache, backing := New[int, int](), New[int, int]()
+	cnt, _ := NewCache(cache, backing, WriteThrough)
+	ctx := context.Background()
+
+	cnt.Put(ctx, 1, 1)
+
+	val, _ := cache.Get(ctx, 1)
+	assertEq("cache val", 1, val, func(s string) { t.Fatal(s) })
+	val, _ = backing.Get(ctx, 1)
+	assertEq("backing val", 1, val, func(s string) { t.Fatal(s) })
+
+	// Mod hits the backing container and invalidates the cache.
+	err := cnt.Mod(ctx, 1, func(v int) int { return v + 1 })
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	_, err = cache.Get(ctx, 1)
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+
+	val, _ = cnt.Get(ctx, 1)
+	assertEq("val", 2, val, func(s string) { t.Fatal(s) })
+
+	// Del removes from both.
+	val, err = cnt.Del(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 2, val, func(s string) { t.Fatal(s) })
+
+	_, err = cache.Get(ctx, 1)
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+	_, err = backing.Get(ctx, 1)
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+}
+
+func TestNewCacheWriteThroughBackingErr(t *testing.T) {
+	cache := New[int, int]()
+	backing := ContainerImpl[int, int]{}
+	cnt, _ := NewCache[int, int](cache, backing, WriteThrough)
+	ctx := context.Background()
+
+	cache.Put(ctx, 1, 1)
+
+	err := cnt.Put(ctx, 1, 2)
+	assertEq("err", true, errors.Is(err, ErrImpl), func(s string) { t.Fatal(s) })
+
+	// The failed write must not leave a stale value in the cache.
+	_, err = cache.Get(ctx, 1)
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+}
+
+func TestNewCacheWriteAround(t *testing.T) {
+	cache, backing := New[int, int](), New[int, int]()
+	cnt, _ := NewCache(cache, backing, WriteAround)
+	ctx := context.Background()
+
+	cache.Put(ctx, 1, 0)
+	cnt.Put(ctx, 1, 1)
+
+	_, err := cache.Get(ctx, 1)
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+
+	val, _ := backing.Get(ctx, 1)
+	assertEq("backing val", 1, val, func(s string) { t.Fatal(s) })
+}
+
+func TestNewCacheWriteBack(t *testing.T) {
+	cache, backing := New[int, int](), New[int, int]()
+	cnt, flush := NewCache(cache, backing, WriteBack)
+	ctx := context.Background()
+
+	backing.Put(ctx, 2, 20)
+
+	cnt.Put(ctx, 1, 1)
+	cnt.Mod(ctx, 2, func(v int) int { return v + 1 })
+
+	_, err := backing.Get(ctx, 1)
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+	val, _ := backing.Get(ctx, 2)
+	assertEq("backing val", 20, val, func(s string) { t.Fatal(s) })
+
+	err = flush(ctx)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	val, _ = backing.Get(ctx, 1)
+	assertEq("backing val", 1, val, func(s string) { t.Fatal(s) })
+	val, _ = backing.Get(ctx, 2)
+	assertEq("backing val", 21, val, func(s string) { t.Fatal(s) })
+}
+
+func TestNewCacheWriteBackDel(t *testing.T) {
+	cache, backing := New[int, int](), New[int, int]()
+	cnt, flush := NewCache(cache, backing, WriteBack)
+	ctx := context.Background()
+
+	// A dirty key which never reached the backing container can be deleted.
+	cnt.Put(ctx, 1, 1)
+	val, err := cnt.Del(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 1, val, func(s string) { t.Fatal(s) })
+
+	err = flush(ctx)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	_, err = backing.Get(ctx, 1)
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+}
+
+func TestNewCacheWriteBackModBackingErr(t *testing.T) {
+	backing := New[int, int]()
+	ctx := context.Background()
+	backing.Put(ctx, 1, 100)
+
+	// A backing container whose reads fail.
+	flaky := ContainerImpl[int, int]{
+		PutterImpl: PutterImpl[int, int]{Impl: backing.Put},
+		GetterImpl: GetterImpl[int, int]{Impl: func(_ context.Context, k int) (int, error) {
+			return 0, &OpError{Op: OpGet, Key: k, Err: errFlaky}
+		}},
+		ModifierImpl: ModifierImpl[int, int]{Impl: backing.Mod},
+		DeleterImpl:  DeleterImpl[int, int]{Impl: backing.Del},
+	}
+
+	cache := New[int, int]()
+	cnt, flush := NewCache[int, int](cache, flaky, WriteBack)
+
+	err := cnt.Mod(ctx, 1, func(v int) int { return v + 1 })
+	assertEq("err", true, errors.Is(err, errFlaky), func(s string) { t.Fatal(s) })
+	assertEq("not found", false, errors.Is(err, ErrNotFound), func(s string) { t.Fatal(s) })
+	assertEq("op", true, errors.Is(err, ErrMod), func(s string) { t.Fatal(s) })
+
+	// The key is neither modified nor dirty, so the backing value survives.
+	_, err = cache.Get(ctx, 1)
+	assertEq("cache", true, errors.Is(err, ErrNotFound), func(s string) { t.Fatal(s) })
+
+	err = flush(ctx)
+	assertEq("flush err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	val, _ := backing.Get(ctx, 1)
+	assertEq("backing val", 100, val, func(s string) { t.Fatal(s) })
+}
+
+func TestNewCacheWriteBackDelBackingErr(t *testing.T) {
+	backing := New[int, int]()
+	ctx := context.Background()
+	backing.Put(ctx, 1, 1)
+
+	// A backing container whose deletes fail.
+	flaky := ContainerImpl[int, int]{
+		PutterImpl:   PutterImpl[int, int]{Impl: backing.Put},
+		GetterImpl:   GetterImpl[int, int]{Impl: backing.Get},
+		ModifierImpl: ModifierImpl[int, int]{Impl: backing.Mod},
+		DeleterImpl:  DeleterImpl[int, int]{Impl: func(context.Context, int) (int, error) { return 0, errFlaky }},
+	}
+
+	cnt, flush := NewCache[int, int](New[int, int](), flaky, WriteBack)
+	cnt.Put(ctx, 1, 2)
+
+	_, err := cnt.Del(ctx, 1)
+	assertEq("err", true, errors.Is(err, errFlaky), func(s string) { t.Fatal(s) })
+
+	// The dirty value is neither lost, nor replaced by the backing value.
+	val, _ := cnt.Get(ctx, 1)
+	assertEq("val", 2, val, func(s string) { t.Fatal(s) })
+
+	flush(ctx)
+	val, _ = backing.Get(ctx, 1)
+	assertEq("flushed", 2, val, func(s string) { t.Fatal(s) })
+}
+
+func TestNewCacheWriteBackLen(t *testing.T) {
+	backing := New[int, int]()
+	cnt, flush := NewCache[int, int](New[int, int](), backing, WriteBack)
+	ctx := context.Background()
+
+	backing.Put(ctx, 1, 1)
+	cnt.Put(ctx, 1, 10)
+	cnt.Put(ctx, 2, 2)
+
+	n, err := cnt.Len(ctx)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("len", 2, n, func(s string) { t.Fatal(s) })
+
+	flush(ctx)
+	n, _ = cnt.Len(ctx)
+	assertEq("flushed len", 2, n, func(s string) { t.Fatal(s) })
+}
+
+func TestNewCacheLocksPerKey(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	backing, blocked := blockingContainer(New[int, int](), 1, release)
+	cnt, _ := NewCache[int, int](New[int, int](), backing, WriteThrough)
+	ctx := context.Background()
+
+	go cnt.Get(ctx, 1)
+	<-blocked
+
+	// Calls for other keys do not wait for the blocked one.
+	within(t, func() {
+		cnt.Put(ctx, 2, 2)
+		val, _ := cnt.Get(ctx, 2)
+		assertEq("val", 2, val, func(s string) { t.Error(s) })
+	})
+}
@@ -0,0 +1,28 @@
+package gontainer
+
+import "time"
+
+// Clock is the source of time used by decorators which depend on it, so that
+// they can be tested without depending on wall time.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// wallClock implements Clock with the time package.
+type wallClock struct{}
+
+// Now implements Clock.Now with time.Now.
+func (wallClock) Now() time.Time { return time.Now() }
+
+// After implements Clock.After with time.After.
+func (wallClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// clockOr returns "c", or the wall clock if "c" is nil.
+func clockOr(c Clock) Clock {
+	if c == nil {
+		return wallClock{}
+	}
+
+	return c
+}
@@ -0,0 +1,79 @@
+package gontainer
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// fakeClock implements Clock for tests. Time only moves with Advance.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Now()}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward and fires all waiters which are due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			waiters = append(waiters, w)
+			continue
+		}
+
+		w.ch <- c.now
+	}
+
+	c.waiters = waiters
+}
+
+// BlockUntil waits until there are at least "n" pending calls to After.
+func (c *fakeClock) BlockUntil(n int) {
+	for {
+		c.mu.Lock()
+		l := len(c.waiters)
+		c.mu.Unlock()
+
+		if l >= n {
+			return
+		}
+
+		runtime.Gosched()
+	}
+}
@@ -0,0 +1,136 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// flight coalesces concurrent calls with the same key into one.
+type flight[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*flightCall[V]
+}
+
+// flightCall is a call in flight, the result is set before done is closed.
+type flightCall[V any] struct {
+	done chan struct{}
+	val  V
+	err  error
+}
+
+// do calls "f" unless a call for "key" is already in flight, in which case it
+// waits for that call and returns its result instead. The first caller makes
+// the call with its own ctx, while the others only wait for as long as their
+// own ctx allows; they then fail with an OpError for "op" which wraps the ctx
+// error. If "f" panics, the panic is passed on to the first caller, and the
+// others fail with an OpError which says so.
+func (fl *flight[K, V]) do(
+	ctx context.Context,
+	op Op,
+	key K,
+	f func(ctx context.Context) (V, error),
+) (
+	val V,
+	err error,
+) {
+	fl.mu.Lock()
+	if fl.calls == nil {
+		fl.calls = make(map[K]*flightCall[V])
+	}
+
+	if c, ok := fl.calls[key]; ok {
+		fl.mu.Unlock()
+
+		select {
+		case <-c.done:
+			return c.val, c.err
+		case <-ctx.Done():
+			err = &OpError{Op: op, Key: key, Err: ctx.Err()}
+			return
+		}
+	}
+
+	c := &flightCall[V]{done: make(chan struct{})}
+	fl.calls[key] = c
+	fl.mu.Unlock()
+
+	// recover returns nil if "f" exits with runtime.Goexit (e.g. t.FailNow),
+	// so a flag tells whether it returned normally.
+	returned := false
+	defer func() {
+		r := recover()
+		switch {
+		case r != nil:
+			c.val = *new(V)
+			c.err = &OpError{Op: op, Key: key, Err: fmt.Errorf("gontainer: coalesced call panicked: %v", r)}
+		case !returned:
+			c.val = *new(V)
+			c.err = &OpError{Op: op, Key: key, Err: errors.New("gontainer: coalesced call did not return")}
+		}
+
+		fl.mu.Lock()
+		delete(fl.calls, key)
+		fl.mu.Unlock()
+		close(c.done)
+
+		if r != nil {
+			panic(r)
+		}
+	}()
+
+	c.val, c.err = f(ctx)
+	returned = true
+	return c.val, c.err
+}
+
+// CoalesceGetter decorates "g" such that concurrent calls to Get for the same
+// key are collapsed into a single call to "g", of which the value and error
+// are shared by all callers. The call is made with the ctx of the first
+// caller, so if it is canceled, the error is shared as well. Other callers
+// stop waiting if their own ctx is done. If the call panics, the first caller
+// panics, while the others get an error.
+//
+// Note, callers share the same value, so it should not be mutated if it is
+// (or contains) a pointer, slice or map.
+func CoalesceGetter[K comparable, V any](g Getter[K, V]) Getter[K, V] {
+	return coalesceGetter(g)
+}
+
+func coalesceGetter[K comparable, V any](g Getter[K, V]) GetterImpl[K, V] {
+	fl := &flight[K, V]{}
+	return GetterImpl[K, V]{
+		Impl: func(ctx context.Context, key K) (val V, err error) {
+			return fl.do(ctx, OpGet, key, func(ctx context.Context) (V, error) {
+				return g.Get(ctx, key)
+			})
+		},
+	}
+}
+
+// CoalesceContainer decorates "c" such that Get is coalesced as explained for
+// CoalesceGetter. All other calls are forwarded as they are.
+func CoalesceContainer[K comparable, V any](c Container[K, V]) Container[K, V] {
+	return ContainerImpl[K, V]{
+		PutterImpl:   PutterImpl[K, V]{Impl: c.Put},
+		GetterImpl:   coalesceGetter[K, V](c),
+		ModifierImpl: ModifierImpl[K, V]{Impl: c.Mod},
+		DeleterImpl:  DeleterImpl[K, V]{Impl: c.Del},
+		ImplLen:      c.Len,
+		ImplCap:      c.Cap,
+	}
+}
+
+// CoalesceSearcher decorates "s" such that concurrent calls to Search with
+// identical filters are coalesced, as explained for CoalesceGetter.
+func CoalesceSearcher[Q comparable, R any](s Searcher[Q, R]) Searcher[Q, R] {
+	fl := &flight[Q, R]{}
+	return SearcherImpl[Q, R]{
+		Impl: func(ctx context.Context, filter Q) (r R, err error) {
+			return fl.do(ctx, OpSearch, filter, func(ctx context.Context) (R, error) {
+				return s.Search(ctx, filter)
+			})
+		},
+	}
+}
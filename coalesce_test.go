@@ -0,0 +1,246 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowGetter returns a Getter which blocks until "release" is closed, and
+// counts how many calls it gets.
+func slowGetter(release chan struct{}, calls *atomic.Int32) GetterImpl[int, int] {
+	return GetterImpl[int, int]{
+		Impl: func(_ context.Context, key int) (int, error) {
+			calls.Add(1)
+			<-release
+			return key * 10, errFlaky
+		},
+	}
+}
+
+// waitCalls waits until "calls" is above zero.
+func waitCalls(calls *atomic.Int32) {
+	for calls.Load() == 0 {
+		runtime.Gosched()
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Tests for CoalesceGetter & co.
+// -----------------------------------------------------------------------------
+
+func TestCoalesceGetter(t *testing.T) {
+	release := make(chan struct{})
+	calls := &atomic.Int32{}
+	g := CoalesceGetter[int, int](slowGetter(release, calls))
+
+	const callers = 16
+
+	vals := make([]int, callers)
+	errs := make([]error, callers)
+
+	wg := sync.WaitGroup{}
+	started := sync.WaitGroup{}
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		started.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			started.Done()
+			vals[i], errs[i] = g.Get(context.Background(), 1)
+		}(i)
+	}
+
+	// Wait for the first call to be in flight, then give the rest time to
+	// join it before releasing.
+	started.Wait()
+	waitCalls(calls)
+	time.Sleep(10 * time.Millisecond)
+
+	close(release)
+	wg.Wait()
+
+	for i := 0; i < callers; i++ {
+		assertEq("val", 10, vals[i], func(s string) { t.Fatal(s) })
+		assertEq("err", true, errors.Is(errs[i], errFlaky), func(s string) { t.Fatal(s) })
+	}
+
+	assertEq("calls", int32(1), calls.Load(), func(s string) { t.Fatal(s) })
+}
+
+func TestCoalesceGetterDistinctKeys(t *testing.T) {
+	release := make(chan struct{})
+	close(release)
+
+	calls := &atomic.Int32{}
+	g := CoalesceGetter[int, int](slowGetter(release, calls))
+
+	val, _ := g.Get(context.Background(), 1)
+	assertEq("val", 10, val, func(s string) { t.Fatal(s) })
+	val, _ = g.Get(context.Background(), 2)
+	assertEq("val", 20, val, func(s string) { t.Fatal(s) })
+
+	// Calls which are not concurrent are not coalesced.
+	g.Get(context.Background(), 1)
+	assertEq("calls", int32(3), calls.Load(), func(s string) { t.Fatal(s) })
+}
+
+func TestCoalesceGetterWaiterCtx(t *testing.T) {
+	release := make(chan struct{})
+	calls := &atomic.Int32{}
+	g := CoalesceGetter[int, int](slowGetter(release, calls))
+
+	go g.Get(context.Background(), 1)
+	waitCalls(calls)
+
+	// A waiter stops waiting when its own ctx is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := g.Get(ctx, 1)
+	assertEq("err", true, errors.Is(err, context.Canceled), func(s string) { t.Fatal(s) })
+	assertEq("op", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+
+	close(release)
+}
+
+func TestCoalesceContainer(t *testing.T) {
+	cnt := CoalesceContainer(New[int, int]())
+	ctx := context.Background()
+
+	err := cnt.Put(ctx, 1, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	err = cnt.Mod(ctx, 1, func(v int) int { return v + 1 })
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	val, err := cnt.Get(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 2, val, func(s string) { t.Fatal(s) })
+
+	n, _ := cnt.Len(ctx)
+	assertEq("len", 1, n, func(s string) { t.Fatal(s) })
+
+	val, err = cnt.Del(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 2, val, func(s string) { t.Fatal(s) })
+}
+
+func TestCoalesceSearcher(t *testing.T) {
+	release := make(chan struct{})
+	calls := &atomic.Int32{}
+
+	s := SearcherImpl[string, int]{}
+	s.Impl = func(context.Context, string) (int, error) {
+		calls.Add(1)
+		<-release
+		return 1, nil
+	}
+
+	cs := CoalesceSearcher[string, int](s)
+
+	wg := sync.WaitGroup{}
+	started := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		started.Add(1)
+		go func() {
+			defer wg.Done()
+			started.Done()
+			r, err := cs.Search(context.Background(), "q")
+			assertEq("err", *new(error), err, func(s string) { t.Error(s) })
+			assertEq("r", 1, r, func(s string) { t.Error(s) })
+		}()
+	}
+
+	// See TestCoalesceGetter.
+	started.Wait()
+	waitCalls(calls)
+	time.Sleep(10 * time.Millisecond)
+
+	close(release)
+	wg.Wait()
+
+	assertEq("calls", int32(1), calls.Load(), func(s string) { t.Fatal(s) })
+}
+
+func TestCoalesceGetterPanic(t *testing.T) {
+	release := make(chan struct{})
+	calls := &atomic.Int32{}
+
+	g := GetterImpl[int, int]{}
+	g.Impl = func(context.Context, int) (int, error) {
+		calls.Add(1)
+		<-release
+		panic("boom")
+	}
+
+	cg := CoalesceGetter[int, int](g)
+
+	leader := make(chan any)
+	go func() {
+		defer func() { leader <- recover() }()
+		cg.Get(context.Background(), 1)
+	}()
+
+	waitCalls(calls)
+
+	waiter := make(chan error)
+	go func() {
+		_, err := cg.Get(context.Background(), 1)
+		waiter <- err
+	}()
+
+	// Give the waiter time to join the call.
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	assertEq("leader", any("boom"), <-leader, func(s string) { t.Fatal(s) })
+
+	err := <-waiter
+	assertEq("waiter err", true, err != nil, func(s string) { t.Fatal(s) })
+	assertEq("waiter op", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+	assertEq("calls", int32(1), calls.Load(), func(s string) { t.Fatal(s) })
+}
+
+func TestCoalesceGetterGoexit(t *testing.T) {
+	release := make(chan struct{})
+	calls := &atomic.Int32{}
+
+	g := GetterImpl[int, int]{}
+	g.Impl = func(context.Context, int) (int, error) {
+		calls.Add(1)
+		<-release
+		runtime.Goexit()
+		return 0, nil
+	}
+
+	cg := CoalesceGetter[int, int](g)
+
+	leader := make(chan struct{})
+	go func() {
+		defer close(leader)
+		cg.Get(context.Background(), 1)
+	}()
+
+	waitCalls(calls)
+
+	waiter := make(chan error)
+	go func() {
+		_, err := cg.Get(context.Background(), 1)
+		waiter <- err
+	}()
+
+	// Give the waiter time to join the call.
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	<-leader
+
+	err := <-waiter
+	assertEq("waiter err", true, err != nil, func(s string) { t.Fatal(s) })
+	assertEq("waiter op", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+	assertEq("calls", int32(1), calls.Load(), func(s string) { t.Fatal(s) })
+}
@@ -0,0 +1,90 @@
+package gontainer_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/crunchypi/gontainer"
+	"github.com/crunchypi/gontainer/gontainertest"
+)
+
+func TestNewConformance(t *testing.T) {
+	gontainertest.RunContainerSuite(t, gontainertest.Factory[int, string]{
+		New:        func() gontainer.Container[int, string] { return gontainer.New[int, string]() },
+		Key:        func(i int) int { return i },
+		Val:        func(i int) string { return "v" + strconv.Itoa(i) },
+		ModUpserts: true,
+	})
+}
+
+func TestNewShardedConformance(t *testing.T) {
+	gontainertest.RunContainerSuite(t, gontainertest.Factory[int, string]{
+		New:        func() gontainer.Container[int, string] { return gontainer.NewSharded[int, string](4, nil) },
+		Key:        func(i int) int { return i },
+		Val:        func(i int) string { return "v" + strconv.Itoa(i) },
+		ModUpserts: true,
+	})
+}
+
+func TestNewVersionedConformance(t *testing.T) {
+	gontainertest.RunContainerSuite(t, gontainertest.Factory[int, string]{
+		New:        func() gontainer.Container[int, string] { return gontainer.NewVersioned[int, string]() },
+		Key:        func(i int) int { return i },
+		Val:        func(i int) string { return "v" + strconv.Itoa(i) },
+		ModUpserts: true,
+	})
+}
+
+func TestNewLRUConformance(t *testing.T) {
+	gontainertest.RunContainerSuite(t, gontainertest.Factory[int, string]{
+		New:        func() gontainer.Container[int, string] { return gontainer.NewLRU[int, string](1024, nil) },
+		Key:        func(i int) int { return i },
+		Val:        func(i int) string { return "v" + strconv.Itoa(i) },
+		ModUpserts: true,
+	})
+}
+
+func TestNewTTLConformance(t *testing.T) {
+	gontainertest.RunContainerSuite(t, gontainertest.Factory[int, string]{
+		New: func() gontainer.Container[int, string] {
+			cnt, stop := gontainer.NewTTL(gontainer.New[int, string](), gontainer.TTLConfig{TTL: time.Hour})
+			t.Cleanup(stop)
+			return cnt
+		},
+		Key:        func(i int) int { return i },
+		Val:        func(i int) string { return "v" + strconv.Itoa(i) },
+		ModUpserts: true,
+	})
+}
+
+func TestNewCacheConformance(t *testing.T) {
+	for name, policy := range map[string]gontainer.WritePolicy{
+		"WriteThrough": gontainer.WriteThrough,
+		"WriteBack":    gontainer.WriteBack,
+		"WriteAround":  gontainer.WriteAround,
+	} {
+		t.Run(name, func(t *testing.T) {
+			gontainertest.RunContainerSuite(t, gontainertest.Factory[int, string]{
+				New: func() gontainer.Container[int, string] {
+					cnt, _ := gontainer.NewCache(gontainer.New[int, string](), gontainer.New[int, string](), policy)
+					return cnt
+				},
+				Key:        func(i int) int { return i },
+				Val:        func(i int) string { return "v" + strconv.Itoa(i) },
+				ModUpserts: true,
+			})
+		})
+	}
+}
+
+func TestVersioningConformance(t *testing.T) {
+	gontainertest.RunContainerSuite(t, gontainertest.Factory[int, string]{
+		New: func() gontainer.Container[int, string] {
+			return gontainer.Versioning(gontainer.New[int, gontainer.VersionedVal[string]](), gontainer.VersioningConfig{})
+		},
+		Key:        func(i int) int { return i },
+		Val:        func(i int) string { return "v" + strconv.Itoa(i) },
+		ModUpserts: true,
+	})
+}
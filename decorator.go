@@ -0,0 +1,323 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// -----------------------------------------------------------------------------
+// Operations.
+// -----------------------------------------------------------------------------
+
+// Op identifies an operation defined by one of the core interfaces.
+type Op string
+
+const (
+	OpPut          Op = "put"
+	OpGet          Op = "get"
+	OpMod          Op = "mod"
+	OpDel          Op = "del"
+	OpLen          Op = "len"
+	OpCap          Op = "cap"
+	OpSearch       Op = "search"
+	OpSearchUpdate Op = "search_update"
+	OpSearchDelete Op = "search_delete"
+	OpPage         Op = "page"
+)
+
+// sentinel returns the error which represents a failure of the operation, or
+// nil for Len, Cap and Page.
+func (op Op) sentinel() error {
+	switch op {
+	case OpPut:
+		return ErrPut
+	case OpGet:
+		return ErrGet
+	case OpMod:
+		return ErrMod
+	case OpDel:
+		return ErrDel
+	case OpSearch:
+		return ErrSearcher
+	case OpSearchUpdate:
+		return ErrSearchUpdater
+	case OpSearchDelete:
+		return ErrSearchDeleter
+	}
+
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// Decorator.
+// -----------------------------------------------------------------------------
+
+// Decorator intercepts a call to an operation. It is given the operation and
+// the key (the filter for Search operations, nil for Len and Cap), along with
+// "call" which does the actual work. A Decorator is expected to invoke "call"
+// with the ctx it wants the operation to use, and return the resulting error
+// unless it has a reason not to. A Decorator may return before "call" does
+// (e.g. on a timeout), any value produced by the call after that is dropped.
+//
+// A Decorator is applied to an implementation with one of the Decorate funcs,
+// such as DecorateContainer or DecorateGetter. These make sure that errors are
+// returned as an *OpError, by wrapping those which do not contain one.
+type Decorator func(
+	ctx context.Context,
+	op Op,
+	key any,
+	call func(ctx context.Context) error,
+) (
+	err error,
+)
+
+// Chain combines decorators into one. The first decorator is the outermost,
+// i.e. it sees the call first and the error last.
+func Chain(ds ...Decorator) Decorator {
+	return func(
+		ctx context.Context,
+		op Op,
+		key any,
+		call func(ctx context.Context) error,
+	) (
+		err error,
+	) {
+		for i := len(ds) - 1; i >= 0; i-- {
+			d, next := ds[i], call
+			call = func(ctx context.Context) error { return d(ctx, op, key, next) }
+		}
+
+		return call(ctx)
+	}
+}
+
+// NameDecorator returns a Decorator which sets "name" as the OpError.Name of
+// errors which do not have a name yet, so that they identify the container.
+func NameDecorator(name string) Decorator {
+	return func(
+		ctx context.Context,
+		op Op,
+		key any,
+		call func(ctx context.Context) error,
+	) (
+		err error,
+	) {
+		err = opError(op, key, call(ctx))
+
+		var opErr *OpError
+		if !errors.As(err, &opErr) || opErr.Name != "" {
+			return
+		}
+
+		// Copy rather than modify, since the error is not ours. If it is not
+		// on the top of the chain, then it is not ours to replace either.
+		if err != error(opErr) {
+			return &OpError{Op: op, Key: key, Name: name, Err: err}
+		}
+
+		named := *opErr
+		named.Name = name
+		return &named
+	}
+}
+
+// result holds a value produced by a call. A Decorator may call more than once
+// (e.g. on retry), and may return before a call does (e.g. on a timeout). So
+// each call gets its own attempt, and only the value of the latest attempt is
+// kept; a late value of an earlier attempt, or of any attempt after get, is
+// dropped rather than racing with the caller which reads it.
+type result[T any] struct {
+	mu       sync.Mutex
+	val      T
+	attempts int
+	done     bool
+}
+
+// attempt starts a call, and returns the func which sets its value.
+func (r *result[T]) attempt() (set func(v T)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.attempts++
+	n := r.attempts
+	return func(v T) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if !r.done && n == r.attempts {
+			r.val = v
+		}
+	}
+}
+
+// get returns the kept value, and drops any later value.
+func (r *result[T]) get() T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.done = true
+	return r.val
+}
+
+// DecoratePutter applies "d" to Put of "p".
+func DecoratePutter[K comparable, V any](p Putter[K, V], d Decorator) Putter[K, V] {
+	return decoratePutter(p, d)
+}
+
+func decoratePutter[K comparable, V any](p Putter[K, V], d Decorator) PutterImpl[K, V] {
+	return PutterImpl[K, V]{
+		Impl: func(ctx context.Context, key K, val V) (err error) {
+			err = d(ctx, OpPut, key, func(ctx context.Context) error {
+				return p.Put(ctx, key, val)
+			})
+
+			return opError(OpPut, key, err)
+		},
+	}
+}
+
+// DecorateGetter applies "d" to Get of "g".
+func DecorateGetter[K comparable, V any](g Getter[K, V], d Decorator) Getter[K, V] {
+	return decorateGetter(g, d)
+}
+
+func decorateGetter[K comparable, V any](g Getter[K, V], d Decorator) GetterImpl[K, V] {
+	return GetterImpl[K, V]{
+		Impl: func(ctx context.Context, key K) (val V, err error) {
+			res := &result[V]{}
+			err = d(ctx, OpGet, key, func(ctx context.Context) error {
+				set := res.attempt()
+				val, err := g.Get(ctx, key)
+				set(val)
+				return err
+			})
+
+			return res.get(), opError(OpGet, key, err)
+		},
+	}
+}
+
+// DecorateModifier applies "d" to Mod of "m".
+func DecorateModifier[K comparable, V any](m Modifier[K, V], d Decorator) Modifier[K, V] {
+	return decorateModifier(m, d)
+}
+
+func decorateModifier[K comparable, V any](m Modifier[K, V], d Decorator) ModifierImpl[K, V] {
+	return ModifierImpl[K, V]{
+		Impl: func(ctx context.Context, key K, rcv func(v V) V) (err error) {
+			err = d(ctx, OpMod, key, func(ctx context.Context) error {
+				return m.Mod(ctx, key, rcv)
+			})
+
+			return opError(OpMod, key, err)
+		},
+	}
+}
+
+// DecorateDeleter applies "d" to Del of "dl".
+func DecorateDeleter[K comparable, V any](dl Deleter[K, V], d Decorator) Deleter[K, V] {
+	return decorateDeleter(dl, d)
+}
+
+func decorateDeleter[K comparable, V any](dl Deleter[K, V], d Decorator) DeleterImpl[K, V] {
+	return DeleterImpl[K, V]{
+		Impl: func(ctx context.Context, key K) (val V, err error) {
+			res := &result[V]{}
+			err = d(ctx, OpDel, key, func(ctx context.Context) error {
+				set := res.attempt()
+				val, err := dl.Del(ctx, key)
+				set(val)
+				return err
+			})
+
+			return res.get(), opError(OpDel, key, err)
+		},
+	}
+}
+
+// DecorateSearcher applies "d" to Search of "s".
+func DecorateSearcher[Q, R any](s Searcher[Q, R], d Decorator) Searcher[Q, R] {
+	return SearcherImpl[Q, R]{
+		Impl: func(ctx context.Context, filter Q) (r R, err error) {
+			res := &result[R]{}
+			err = d(ctx, OpSearch, filter, func(ctx context.Context) error {
+				set := res.attempt()
+				r, err := s.Search(ctx, filter)
+				set(r)
+				return err
+			})
+
+			return res.get(), opError(OpSearch, filter, err)
+		},
+	}
+}
+
+// DecorateSearchUpdater applies "d" to SearchUpdate of "s".
+func DecorateSearchUpdater[Q, U, R any](
+	s SearchUpdater[Q, U, R],
+	d Decorator,
+) SearchUpdater[Q, U, R] {
+	return SearchUpdaterImpl[Q, U, R]{
+		Impl: func(ctx context.Context, filter Q, update U) (r R, err error) {
+			res := &result[R]{}
+			err = d(ctx, OpSearchUpdate, filter, func(ctx context.Context) error {
+				set := res.attempt()
+				r, err := s.SearchUpdate(ctx, filter, update)
+				set(r)
+				return err
+			})
+
+			return res.get(), opError(OpSearchUpdate, filter, err)
+		},
+	}
+}
+
+// DecorateSearchDeleter applies "d" to SearchDelete of "s".
+func DecorateSearchDeleter[Q, R any](s SearchDeleter[Q, R], d Decorator) SearchDeleter[Q, R] {
+	return SearchDeleterImpl[Q, R]{
+		Impl: func(ctx context.Context, filter Q) (r R, err error) {
+			res := &result[R]{}
+			err = d(ctx, OpSearchDelete, filter, func(ctx context.Context) error {
+				set := res.attempt()
+				r, err := s.SearchDelete(ctx, filter)
+				set(r)
+				return err
+			})
+
+			return res.get(), opError(OpSearchDelete, filter, err)
+		},
+	}
+}
+
+// DecorateContainer applies "d" to all methods of "c", including Len and Cap.
+func DecorateContainer[K comparable, V any](c Container[K, V], d Decorator) Container[K, V] {
+	return ContainerImpl[K, V]{
+		PutterImpl:   decoratePutter[K, V](c, d),
+		GetterImpl:   decorateGetter[K, V](c, d),
+		ModifierImpl: decorateModifier[K, V](c, d),
+		DeleterImpl:  decorateDeleter[K, V](c, d),
+		ImplLen: func(ctx context.Context) (n int, err error) {
+			res := &result[int]{}
+			err = d(ctx, OpLen, nil, func(ctx context.Context) error {
+				set := res.attempt()
+				n, err := c.Len(ctx)
+				set(n)
+				return err
+			})
+
+			return res.get(), opError(OpLen, nil, err)
+		},
+		ImplCap: func(ctx context.Context) (n int, err error) {
+			res := &result[int]{}
+			err = d(ctx, OpCap, nil, func(ctx context.Context) error {
+				set := res.attempt()
+				n, err := c.Cap(ctx)
+				set(n)
+				return err
+			})
+
+			return res.get(), opError(OpCap, nil, err)
+		},
+	}
+}
@@ -0,0 +1,190 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+// recordOps returns a Decorator which appends each op it sees to "ops".
+func recordOps(ops *[]Op) Decorator {
+	return func(
+		ctx context.Context,
+		op Op,
+		key any,
+		call func(ctx context.Context) error,
+	) error {
+		*ops = append(*ops, op)
+		return call(ctx)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Tests for Decorate funcs.
+// -----------------------------------------------------------------------------
+
+func TestDecorateContainer(t *testing.T) {
+	ops := []Op{}
+	cnt := DecorateContainer(New[int, int](), recordOps(&ops))
+	ctx := context.Background()
+
+	err := cnt.Put(ctx, 1, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	val, err := cnt.Get(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 1, val, func(s string) { t.Fatal(s) })
+
+	err = cnt.Mod(ctx, 1, func(v int) int { return v + 1 })
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	n, err := cnt.Len(ctx)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("len", 1, n, func(s string) { t.Fatal(s) })
+
+	n, err = cnt.Cap(ctx)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("cap", 2, n, func(s string) { t.Fatal(s) })
+
+	val, err = cnt.Del(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 2, val, func(s string) { t.Fatal(s) })
+
+	_, err = cnt.Get(ctx, 1)
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+
+	want := []Op{OpPut, OpGet, OpMod, OpLen, OpCap, OpDel, OpGet}
+	assertEq("ops", want, ops, func(s string) { t.Fatal(s) })
+}
+
+func TestDecorateSearchers(t *testing.T) {
+	ops := []Op{}
+	d := recordOps(&ops)
+	ctx := context.Background()
+
+	s := SearcherImpl[int, int]{}
+	s.Impl = func(_ context.Context, q int) (int, error) { return q + 1, nil }
+	r, err := DecorateSearcher[int, int](s, d).Search(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("r", 2, r, func(s string) { t.Fatal(s) })
+
+	su := SearchUpdaterImpl[int, int, int]{}
+	su.Impl = func(_ context.Context, q, u int) (int, error) { return q + u, nil }
+	r, err = DecorateSearchUpdater[int, int, int](su, d).SearchUpdate(ctx, 1, 2)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("r", 3, r, func(s string) { t.Fatal(s) })
+
+	sd := SearchDeleterImpl[int, int]{}
+	_, err = DecorateSearchDeleter[int, int](sd, d).SearchDelete(ctx, 1)
+	assertEq("err", true, errors.Is(err, ErrImpl), func(s string) { t.Fatal(s) })
+
+	want := []Op{OpSearch, OpSearchUpdate, OpSearchDelete}
+	assertEq("ops", want, ops, func(s string) { t.Fatal(s) })
+}
+
+func TestChain(t *testing.T) {
+	order := []string{}
+	mk := func(name string) Decorator {
+		return func(
+			ctx context.Context,
+			op Op,
+			key any,
+			call func(ctx context.Context) error,
+		) error {
+			order = append(order, name+">")
+			err := call(ctx)
+			order = append(order, "<"+name)
+			return err
+		}
+	}
+
+	g := DecorateGetter[int, int](New[int, int](), Chain(mk("a"), mk("b")))
+
+	_, err := g.Get(context.Background(), 1)
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+
+	want := []string{"a>", "b>", "<b", "<a"}
+	assertEq("order", want, order, func(s string) { t.Fatal(s) })
+}
+
+func TestDecorateWrapsOpError(t *testing.T) {
+	// A Decorator which fails on its own, without an OpError.
+	d := func(ctx context.Context, op Op, key any, call func(context.Context) error) error {
+		return errFlaky
+	}
+
+	_, err := DecorateGetter[int, int](New[int, int](), d).Get(context.Background(), 1)
+
+	opErr := &OpError{}
+	assertEq("as", true, errors.As(err, &opErr), func(s string) { t.Fatal(s) })
+	assertEq("op", OpGet, opErr.Op, func(s string) { t.Fatal(s) })
+	assertEq("key", 1, opErr.Key, func(s string) { t.Fatal(s) })
+	assertEq("is ErrGet", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+	assertEq("is cause", true, errors.Is(err, errFlaky), func(s string) { t.Fatal(s) })
+
+	// Errors which already have an OpError are not wrapped again.
+	_, err = DecorateGetter[int, int](New[int, int](), Chain()).Get(context.Background(), 1)
+	assertEq("unwrapped", true, errors.Unwrap(err) == ErrNotFound, func(s string) { t.Fatal(s) })
+}
+
+func TestNameDecorator(t *testing.T) {
+	cnt := DecorateContainer(New[int, int](), NameDecorator("users"))
+
+	_, err := cnt.Get(context.Background(), 1)
+
+	opErr := &OpError{}
+	assertEq("as", true, errors.As(err, &opErr), func(s string) { t.Fatal(s) })
+	assertEq("name", "users", opErr.Name, func(s string) { t.Fatal(s) })
+	assertEq("is ErrGet", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+
+	// The innermost name wins.
+	cnt = DecorateContainer(cnt, NameDecorator("outer"))
+	_, err = cnt.Del(context.Background(), 1)
+	assertEq("as", true, errors.As(err, &opErr), func(s string) { t.Fatal(s) })
+	assertEq("name", "users", opErr.Name, func(s string) { t.Fatal(s) })
+
+	// Errors from decorators are named as well.
+	ro := DecorateContainer(ReadOnly(New[int, int]()), NameDecorator("ro"))
+	err = ro.Put(context.Background(), 1, 1)
+	assertEq("as", true, errors.As(err, &opErr), func(s string) { t.Fatal(s) })
+	assertEq("name", "ro", opErr.Name, func(s string) { t.Fatal(s) })
+	assertEq("is ErrReadOnly", true, errors.Is(err, ErrReadOnly), func(s string) { t.Fatal(s) })
+}
+
+func TestDecorateAbandonedAttempt(t *testing.T) {
+	release := make(chan struct{})
+	abandoned := make(chan struct{})
+
+	calls := atomic.Int32{}
+	g := GetterImpl[int, int]{}
+	g.Impl = func(context.Context, int) (int, error) {
+		if calls.Add(1) == 1 {
+			<-release
+			return 1, nil
+		}
+
+		close(release)
+		return 2, nil
+	}
+
+	// Gives up on the first attempt, like a timeout, and returns from the
+	// second one only after the first one has returned as well.
+	attempt := 0
+	d := func(ctx context.Context, op Op, key any, call func(ctx context.Context) error) error {
+		if attempt++; attempt == 1 {
+			go func() { call(ctx); close(abandoned) }()
+			return errFlaky
+		}
+
+		err := call(ctx)
+		<-abandoned
+		return err
+	}
+
+	d = Chain(RetryDecorator(RetryConfig{Attempts: 2, Clock: &instantClock{}}), d)
+
+	val, err := DecorateGetter[int, int](g, d).Get(context.Background(), 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 2, val, func(s string) { t.Fatal(s) })
+}
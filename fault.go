@@ -0,0 +1,150 @@
+package gontainer
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// LatencyDist is a distribution of latencies. It maps a uniformly random
+// number in [0, 1) to a duration.
+type LatencyDist func(r float64) time.Duration
+
+// FixedLatency returns a LatencyDist which is always "d".
+func FixedLatency(d time.Duration) LatencyDist {
+	return func(float64) time.Duration { return d }
+}
+
+// UniformLatency returns a LatencyDist which is uniform in [lo, hi).
+func UniformLatency(lo, hi time.Duration) LatencyDist {
+	return func(r float64) time.Duration {
+		return lo + time.Duration(r*float64(hi-lo))
+	}
+}
+
+// ExpLatency returns a LatencyDist which is exponential with the given mean,
+// i.e. mostly short with a long tail.
+func ExpLatency(mean time.Duration) LatencyDist {
+	return func(r float64) time.Duration {
+		return time.Duration(-math.Log(1-r) * float64(mean))
+	}
+}
+
+// Fault is the configuration of the faults which are injected into calls of
+// an operation. Rates are probabilities from 0 (never) to 1 (always).
+type Fault struct {
+	// Latency is added before each call. The call fails with the ctx error if
+	// the ctx is done while waiting. No latency if nil.
+	Latency LatencyDist
+	// HangRate is the rate of calls which hang, ignoring their ctx, for Hang
+	// or until the hangs are released (see FaultDecorator). The call is then
+	// made as usual.
+	HangRate float64
+	// Hang is how long hanging calls block. Zero or negative means until the
+	// hangs are released.
+	Hang time.Duration
+	// ErrRate is the rate of calls which fail with Err, without calling
+	// through.
+	ErrRate float64
+	// Err is the injected error. Defaults to ErrFault.
+	Err error
+	// DropRate is the rate of writes which are dropped, i.e. which succeed
+	// without calling through. Writes are Put, Mod, Del, SearchUpdate and
+	// SearchDelete, and the rate is ignored for other operations.
+	DropRate float64
+}
+
+// FaultConfig is used to configure FaultDecorator.
+type FaultConfig struct {
+	// Default is the Fault of operations which are not in Ops.
+	Default Fault
+	// Ops sets the Fault per operation, and overrides Default.
+	Ops map[Op]Fault
+	// Seed seeds the source of randomness, such that runs with the same Seed
+	// and the same sequence of calls inject the same faults.
+	Seed uint64
+	// Clock is used for latency and hangs. Defaults to the wall clock if nil.
+	Clock Clock
+}
+
+// FaultDecorator returns a Decorator which injects faults into calls, for
+// testing how callers behave when a container misbehaves. See Fault for the
+// kinds of faults, which are applied in the order latency, hang, error and
+// dropped write. Calling "release" unblocks all current and future hangs, it
+// is safe to call more than once.
+//
+// Note, the source of randomness is shared by all calls through the returned
+// Decorator, so concurrent calls are only reproducible in aggregate.
+func FaultDecorator(cfg FaultConfig) (d Decorator, release func()) {
+	clock := clockOr(cfg.Clock)
+
+	mu := sync.Mutex{}
+	rng := rand.New(rand.NewPCG(cfg.Seed, cfg.Seed))
+	roll := func() float64 {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return rng.Float64()
+	}
+
+	released := make(chan struct{})
+	releaseOnce := sync.Once{}
+	release = func() {
+		releaseOnce.Do(func() { close(released) })
+	}
+
+	d = func(
+		ctx context.Context,
+		op Op,
+		key any,
+		call func(ctx context.Context) error,
+	) (
+		err error,
+	) {
+		f, ok := cfg.Ops[op]
+		if !ok {
+			f = cfg.Default
+		}
+
+		if f.Latency != nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-clock.After(f.Latency(roll())):
+			}
+		}
+
+		if f.HangRate > 0 && roll() < f.HangRate {
+			var timeout <-chan time.Time
+			if f.Hang > 0 {
+				timeout = clock.After(f.Hang)
+			}
+
+			select {
+			case <-released:
+			case <-timeout:
+			}
+		}
+
+		if f.ErrRate > 0 && roll() < f.ErrRate {
+			if f.Err == nil {
+				return ErrFault
+			}
+
+			return f.Err
+		}
+
+		switch op {
+		case OpPut, OpMod, OpDel, OpSearchUpdate, OpSearchDelete:
+			if f.DropRate > 0 && roll() < f.DropRate {
+				return
+			}
+		}
+
+		return call(ctx)
+	}
+
+	return d, release
+}
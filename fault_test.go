@@ -0,0 +1,131 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Tests for FaultDecorator.
+// -----------------------------------------------------------------------------
+
+// faultErrs returns which of "n" calls to Get failed with ErrFault.
+func faultErrs(cfg FaultConfig, n int) (failed []bool) {
+	d, _ := FaultDecorator(cfg)
+	cnt := DecorateContainer(New[int, int](), d)
+	cnt.Put(context.Background(), 1, 1)
+
+	for i := 0; i < n; i++ {
+		_, err := cnt.Get(context.Background(), 1)
+		failed = append(failed, errors.Is(err, ErrFault))
+	}
+
+	return
+}
+
+func TestFaultDecoratorErrRateSeeded(t *testing.T) {
+	cfg := FaultConfig{Ops: map[Op]Fault{OpGet: {ErrRate: 0.5}}, Seed: 42}
+
+	a := faultErrs(cfg, 200)
+	b := faultErrs(cfg, 200)
+	assertEq("reproducible", a, b, func(s string) { t.Fatal(s) })
+
+	n := 0
+	for _, failed := range a {
+		if failed {
+			n++
+		}
+	}
+
+	assertEq("rate", true, n > 60 && n < 140, func(s string) { t.Fatal(s) })
+
+	cfg.Seed = 43
+	c := faultErrs(cfg, 200)
+	assertEq("seed", false, fmt.Sprint(a) == fmt.Sprint(c), func(s string) { t.Fatal(s) })
+}
+
+func TestFaultDecoratorErr(t *testing.T) {
+	errX := errors.New("x")
+	d, _ := FaultDecorator(FaultConfig{Default: Fault{ErrRate: 1, Err: errX}})
+	cnt := DecorateContainer(New[int, int](), d)
+
+	err := cnt.Put(context.Background(), 1, 1)
+	assertEq("err", true, errors.Is(err, errX), func(s string) { t.Fatal(s) })
+	assertEq("op", true, errors.Is(err, ErrPut), func(s string) { t.Fatal(s) })
+}
+
+func TestFaultDecoratorDrop(t *testing.T) {
+	c := New[int, int]()
+	d, _ := FaultDecorator(FaultConfig{Default: Fault{DropRate: 1}})
+	cnt := DecorateContainer(c, d)
+	ctx := context.Background()
+
+	err := cnt.Put(ctx, 1, 1)
+	assertEq("put err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	// Reads are not dropped.
+	_, err = cnt.Get(ctx, 1)
+	assertEq("get err", true, errors.Is(err, ErrNotFound), func(s string) { t.Fatal(s) })
+
+	n, _ := c.Len(ctx)
+	assertEq("len", 0, n, func(s string) { t.Fatal(s) })
+}
+
+func TestFaultDecoratorLatency(t *testing.T) {
+	clock := newFakeClock()
+	cfg := FaultConfig{Default: Fault{Latency: FixedLatency(time.Second)}, Clock: clock}
+	d, _ := FaultDecorator(cfg)
+	cnt := DecorateContainer(New[int, int](), d)
+
+	done := make(chan error)
+	go func() { done <- cnt.Put(context.Background(), 1, 1) }()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	assertEq("err", *new(error), <-done, func(s string) { t.Fatal(s) })
+
+	// The ctx is respected while waiting.
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { _, err := cnt.Get(ctx, 1); done <- err }()
+
+	clock.BlockUntil(1)
+	cancel()
+	assertEq("ctx err", true, errors.Is(<-done, context.Canceled), func(s string) { t.Fatal(s) })
+}
+
+func TestFaultDecoratorHang(t *testing.T) {
+	d, release := FaultDecorator(FaultConfig{Default: Fault{HangRate: 1}})
+	cnt := DecorateContainer(New[int, int](), d)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan error)
+	go func() { done <- cnt.Put(ctx, 1, 1) }()
+
+	// The hang ignores the canceled ctx.
+	select {
+	case <-done:
+		t.Fatal("want hang")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	release()
+	release()
+	assertEq("err", true, errors.Is(<-done, context.Canceled), func(s string) { t.Fatal(s) })
+}
+
+func TestLatencyDists(t *testing.T) {
+	assertEq("fixed", time.Second, FixedLatency(time.Second)(0.3), func(s string) { t.Fatal(s) })
+
+	u := UniformLatency(time.Second, 3*time.Second)
+	assertEq("uniform lo", time.Second, u(0), func(s string) { t.Fatal(s) })
+	assertEq("uniform mid", 2*time.Second, u(0.5), func(s string) { t.Fatal(s) })
+
+	e := ExpLatency(time.Second)
+	assertEq("exp zero", time.Duration(0), e(0), func(s string) { t.Fatal(s) })
+	assertEq("exp tail", true, e(0.99) > 4*time.Second, func(s string) { t.Fatal(s) })
+}
@@ -1,3 +1,3 @@
 module github.com/crunchypi/gontainer
 
-go 1.22
+go 1.23
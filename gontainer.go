@@ -3,6 +3,9 @@ package gontainer
 import (
 	"context"
 	"errors"
+	"fmt"
+	"iter"
+	"strings"
 )
 
 // -----------------------------------------------------------------------------
@@ -20,6 +23,94 @@ var ErrSearchDeleter = errors.New("gontainer: failed search & update")
 
 var ErrImpl = errors.New("gontainer: used interface without an implementation")
 
+var ErrNotFound = errors.New("gontainer: key not found")
+var ErrExists = errors.New("gontainer: key exists")
+var ErrConflict = errors.New("gontainer: value does not match")
+
+var ErrOpen = errors.New("gontainer: circuit breaker is open")
+var ErrRateLimited = errors.New("gontainer: rate limited")
+
+var ErrReadOnly = errors.New("gontainer: container is read-only")
+var ErrWriteOnly = errors.New("gontainer: container is write-only")
+
+var ErrInvalid = errors.New("gontainer: invalid key or value")
+
+var ErrFault = errors.New("gontainer: injected fault")
+
+// OpError is the error returned by the containers and decorators of this
+// package. It records the operation which failed, on which key, and why. It
+// matches the sentinel of its operation (e.g. ErrGet for OpGet) with
+// errors.Is, in addition to anything matched by "Err".
+type OpError struct {
+	// Op is the operation which failed.
+	Op Op
+	// Key is the key of the operation, the filter for Search operations, and
+	// nil for Len and Cap.
+	Key any
+	// Name is the name of the container, which may be set with NameDecorator.
+	Name string
+	// Err is the underlying cause.
+	Err error
+}
+
+// Error implements error. The "gontainer: " prefix of the cause (as of the
+// sentinels) is dropped, since the message already starts with it.
+func (e *OpError) Error() string {
+	s := "gontainer:"
+	if e.Name != "" {
+		s += " " + e.Name
+	}
+
+	s += " " + string(e.Op)
+	if e.Key != nil {
+		s += fmt.Sprintf(" %v", e.Key)
+	}
+
+	return s + ": " + strings.TrimPrefix(e.Err.Error(), "gontainer: ")
+}
+
+// Unwrap returns the underlying cause.
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether "target" is the sentinel of the operation.
+func (e *OpError) Is(target error) bool {
+	sentinel := e.Op.sentinel()
+	return sentinel != nil && target == sentinel
+}
+
+// opError wraps "err" in an OpError, unless it is nil or already has one.
+func opError(op Op, key any, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var opErr *OpError
+	if errors.As(err, &opErr) {
+		return err
+	}
+
+	return &OpError{Op: op, Key: key, Err: err}
+}
+
+// reOpError wraps "err" in an OpError for "op", unless it is nil. Unlike
+// opError, an OpError on the top of "err" is replaced (keeping its name and
+// cause), so that an error of an inner call (e.g. a Get made by Mod) matches
+// the sentinel of the operation which was actually called.
+func reOpError(op Op, key any, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	name := ""
+	if opErr, ok := err.(*OpError); ok {
+		name, err = opErr.Name, opErr.Err
+	}
+
+	return &OpError{Op: op, Key: key, Name: name, Err: err}
+}
+
 // -----------------------------------------------------------------------------
 // Putter
 // -----------------------------------------------------------------------------
@@ -64,7 +155,8 @@ func (impl PutterImpl[K, V]) Put(
 // Getter.
 // -----------------------------------------------------------------------------
 
-// Getter represents someting which gets a stored value.
+// Getter represents someting which gets a stored value. If the key is
+// missing, the error is expected to wrap ErrNotFound.
 type Getter[K comparable, V any] interface {
 	Get(ctx context.Context, key K) (val V, err error)
 }
@@ -104,7 +196,10 @@ func (impl GetterImpl[K, V]) Get(
 // Modifier.
 // -----------------------------------------------------------------------------
 
-// Modifier represents something which modifies a stored value.
+// Modifier represents something which modifies a stored value. If the key is
+// missing, the error is expected to wrap ErrNotFound. Whether the callback is
+// still called and its result stored in that case depends on the
+// implementation (the default container does so).
 type Modifier[K comparable, V any] interface {
 	Mod(ctx context.Context, key K, rcv func(v V) V) (err error)
 }
@@ -144,7 +239,8 @@ func (impl ModifierImpl[K, V]) Mod(
 // Deleter.
 // -----------------------------------------------------------------------------
 
-// Deleter represents something which deletes a stored value.
+// Deleter represents something which deletes a stored value. If the key is
+// missing, the error is expected to wrap ErrNotFound.
 type Deleter[K comparable, V any] interface {
 	Del(ctx context.Context, key K) (val V, err error)
 }
@@ -350,6 +446,58 @@ func (impl ContainerImpl[K, V]) Cap(
 // -----------------------------------------------------------------------------
 
 // New returns a in-memory container, intended for prototyping and testing.
+// It is safe for concurrent use, and calls to Mod are atomic. It implements
+// Ranger (see All and Keys), Pager, Batcher and Swapper.
 func New[K comparable, V any]() Container[K, V] {
-	return make(mapWrap[K, V])
+	return newMapWrap[K, V]()
+}
+
+// -----------------------------------------------------------------------------
+// Ranger.
+// -----------------------------------------------------------------------------
+
+// Ranger represents something which can list its contents. It is optional,
+// and not part of Container; use the All and Keys funcs to range over a
+// Container which may or may not implement it.
+//
+// Iteration stops early if the ctx is done. The order of iteration is up to
+// the implementation.
+type Ranger[K comparable, V any] interface {
+	All(ctx context.Context) iter.Seq2[K, V]
+	Keys(ctx context.Context) iter.Seq[K]
+}
+
+// RangerImpl lets you implement Ranger with functions. The calls to All and
+// Keys are forwarded to the internal "ImplAll" and "ImplKeys". If "ImplKeys"
+// is nil, Keys uses "ImplAll" instead. If both are nil, the sequences are
+// empty.
+type RangerImpl[K comparable, V any] struct {
+	ImplAll  func(ctx context.Context) iter.Seq2[K, V]
+	ImplKeys func(ctx context.Context) iter.Seq[K]
+}
+
+// All implements Ranger.All by forwarding the call to the internal "ImplAll".
+func (impl RangerImpl[K, V]) All(ctx context.Context) iter.Seq2[K, V] {
+	if impl.ImplAll == nil {
+		return func(yield func(K, V) bool) {}
+	}
+
+	return impl.ImplAll(ctx)
+}
+
+// Keys implements Ranger.Keys by forwarding the call to the internal
+// "ImplKeys", or to "ImplAll" if "ImplKeys" is nil.
+func (impl RangerImpl[K, V]) Keys(ctx context.Context) iter.Seq[K] {
+	if impl.ImplKeys != nil {
+		return impl.ImplKeys(ctx)
+	}
+
+	all := impl.All(ctx)
+	return func(yield func(K) bool) {
+		for k := range all {
+			if !yield(k) {
+				return
+			}
+		}
+	}
 }
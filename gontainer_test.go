@@ -3,7 +3,9 @@ package gontainer
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
 )
 
@@ -26,6 +28,41 @@ func assertEq[T, U any](subject string, a T, b U, f func(string)) {
 	f(fmt.Sprintf(s, subject, as, bs))
 }
 
+// -----------------------------------------------------------------------------
+// Tests for OpError.
+// -----------------------------------------------------------------------------
+
+func TestOpErrorIs(t *testing.T) {
+	err := error(&OpError{Op: OpGet, Key: 1, Err: errFlaky})
+	assertEq("is ErrGet", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+	assertEq("is cause", true, errors.Is(err, errFlaky), func(s string) { t.Fatal(s) })
+	assertEq("is ErrPut", false, errors.Is(err, ErrPut), func(s string) { t.Fatal(s) })
+
+	err = &OpError{Op: OpLen, Err: errFlaky}
+	assertEq("is cause", true, errors.Is(err, errFlaky), func(s string) { t.Fatal(s) })
+	assertEq("is ErrGet", false, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+}
+
+func TestOpErrorAs(t *testing.T) {
+	_, err := New[string, int]().Get(context.Background(), "a")
+
+	opErr := &OpError{}
+	assertEq("as", true, errors.As(err, &opErr), func(s string) { t.Fatal(s) })
+	assertEq("op", OpGet, opErr.Op, func(s string) { t.Fatal(s) })
+	assertEq("key", "a", opErr.Key, func(s string) { t.Fatal(s) })
+}
+
+func TestOpErrorError(t *testing.T) {
+	err := &OpError{Op: OpDel, Key: "a", Name: "users", Err: errFlaky}
+	assertEq("msg", "gontainer: users del a: flaky", err.Error(), func(s string) { t.Fatal(s) })
+
+	err = &OpError{Op: OpLen, Err: errFlaky}
+	assertEq("msg", "gontainer: len: flaky", err.Error(), func(s string) { t.Fatal(s) })
+
+	err = &OpError{Op: OpGet, Key: 1, Err: ErrNotFound}
+	assertEq("msg", "gontainer: get 1: key not found", err.Error(), func(s string) { t.Fatal(s) })
+}
+
 // -----------------------------------------------------------------------------
 // Tests for PutterImpl.
 // -----------------------------------------------------------------------------
@@ -227,7 +264,7 @@ func TestNewGet(t *testing.T) {
 
 	// First call to Get should return an err.
 	val, err = cnt.Get(context.Background(), 1)
-	assertEq("err", ErrGet, err, func(s string) { t.Fatal(s) })
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
 	assertEq("val", 0, val, func(s string) { t.Fatal(s) })
 
 	// Add a value.
@@ -247,7 +284,7 @@ func TestNewMod(t *testing.T) {
 
 	// First mod should upsert, in this case the zero-value + 1.
 	err = cnt.Mod(context.Background(), 1, func(v int) int { return v + 1 })
-	assertEq("err", ErrMod, err, func(s string) { t.Fatal(s) })
+	assertEq("err", true, errors.Is(err, ErrMod), func(s string) { t.Fatal(s) })
 
 	// Validate that the value is there.
 	val, err = cnt.Get(context.Background(), 1)
@@ -280,7 +317,7 @@ func TestNewDel(t *testing.T) {
 
 	// First Del should return an err since nothing was deleted.
 	val, err = cnt.Del(context.Background(), 1)
-	assertEq("err", ErrDel, err, func(s string) { t.Fatal(s) })
+	assertEq("err", true, errors.Is(err, ErrDel), func(s string) { t.Fatal(s) })
 	assertEq("val", 0, val, func(s string) { t.Fatal(s) })
 
 	// Add a value.
@@ -294,7 +331,7 @@ func TestNewDel(t *testing.T) {
 
 	// Validate that the value was deleted.
 	val, err = cnt.Get(context.Background(), 1)
-	assertEq("err", ErrGet, err, func(s string) { t.Fatal(s) })
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
 	assertEq("val", 0, val, func(s string) { t.Fatal(s) })
 }
 
@@ -337,3 +374,99 @@ func TestNewCap(t *testing.T) {
 	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
 	assertEq("len", 2, c, func(s string) { t.Fatal(s) })
 }
+
+func TestNewConcurrent(t *testing.T) {
+	cnt := New[int, int]()
+	ctx := context.Background()
+
+	const workers = 8
+	const keys = 16
+	const iters = 500
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iters; i++ {
+				k := (w + i) % keys
+				cnt.Put(ctx, k, i)
+				cnt.Get(ctx, k)
+				cnt.Mod(ctx, k, func(v int) int { return v + 1 })
+				cnt.Len(ctx)
+				cnt.Cap(ctx)
+				if i%3 == 0 {
+					cnt.Del(ctx, k)
+				}
+			}
+		}(w)
+	}
+
+	wg.Wait()
+}
+
+func TestNewConcurrentModAtomic(t *testing.T) {
+	cnt := New[int, int]()
+	ctx := context.Background()
+
+	const workers = 16
+	const iters = 1000
+
+	cnt.Put(ctx, 1, 0)
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iters; i++ {
+				cnt.Mod(ctx, 1, func(v int) int { return v + 1 })
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	val, err := cnt.Get(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", workers*iters, val, func(s string) { t.Fatal(s) })
+}
+
+func TestNotFoundConformance(t *testing.T) {
+	ttl, stop := NewTTL(New[int, int](), TTLConfig{})
+	defer stop()
+
+	cache, _ := NewCache(New[int, int](), New[int, int](), WriteThrough)
+
+	cnts := map[string]Container[int, int]{
+		"New":        New[int, int](),
+		"NewSharded": NewSharded[int, int](4, nil),
+		"NewLRU":     NewLRU[int, int](8, nil),
+		"NewTTL":     ttl,
+		"NewCache":   cache,
+		"Decorated":  DecorateContainer(New[int, int](), NameDecorator("x")),
+	}
+
+	for name, cnt := range cnts {
+		ctx := context.Background()
+		fatal := func(s string) { t.Fatal(name + ": " + s) }
+
+		_, err := cnt.Get(ctx, 1)
+		assertEq("get not found", true, errors.Is(err, ErrNotFound), fatal)
+		assertEq("get sentinel", true, errors.Is(err, ErrGet), fatal)
+
+		_, err = cnt.Del(ctx, 2)
+		assertEq("del not found", true, errors.Is(err, ErrNotFound), fatal)
+		assertEq("del sentinel", true, errors.Is(err, ErrDel), fatal)
+
+		err = cnt.Mod(ctx, 3, func(v int) int { return v })
+		assertEq("mod not found", true, errors.Is(err, ErrNotFound), fatal)
+		assertEq("mod sentinel", true, errors.Is(err, ErrMod), fatal)
+
+		// Present keys do not match.
+		cnt.Put(ctx, 4, 4)
+		_, err = cnt.Get(ctx, 4)
+		assertEq("get present", false, errors.Is(err, ErrNotFound), fatal)
+		assertEq("exists", false, errors.Is(err, ErrExists), fatal)
+	}
+}
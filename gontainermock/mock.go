@@ -0,0 +1,625 @@
+// Package gontainermock provides recording fakes of the gontainer interfaces.
+//
+// Each fake records the arguments of all calls, and answers them with scripted
+// returns (see the Return methods) in order. When no scripted returns are left,
+// calls are answered by the Impl func of the fake, or with zero values if it is
+// nil. The fakes are safe for concurrent use.
+package gontainermock
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/crunchypi/gontainer"
+)
+
+// ret is a scripted return.
+type ret[T any] struct {
+	val T
+	err error
+}
+
+// mock is the shared core of all fakes. It records calls with arguments A,
+// and keeps a queue of scripted returns of T.
+type mock[A, T any] struct {
+	mu     sync.Mutex
+	calls  []A
+	script []ret[T]
+}
+
+// call records "a" and returns the next scripted return. If there is none,
+// "fallback" is called instead.
+func (m *mock[A, T]) call(a A, fallback func() (T, error)) (val T, err error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, a)
+	if len(m.script) == 0 {
+		m.mu.Unlock()
+		return fallback()
+	}
+
+	r := m.script[0]
+	m.script = m.script[1:]
+	m.mu.Unlock()
+
+	return r.val, r.err
+}
+
+func (m *mock[A, T]) push(val T, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.script = append(m.script, ret[T]{val, err})
+}
+
+func (m *mock[A, T]) recorded() []A {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]A(nil), m.calls...)
+}
+
+func (m *mock[A, T]) count(match func(A) bool) (n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, a := range m.calls {
+		if match == nil || match(a) {
+			n++
+		}
+	}
+
+	return
+}
+
+func (m *mock[A, T]) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = nil
+	m.script = nil
+}
+
+func (m *mock[A, T]) assertCalls(t testing.TB, method string, want int) {
+	t.Helper()
+	if have := m.count(nil); have != want {
+		t.Fatalf("gontainermock: %s called %d times, want %d", method, have, want)
+	}
+}
+
+func (m *mock[A, T]) assertCallsWith(
+	t testing.TB,
+	method string,
+	arg any,
+	want int,
+	match func(A) bool,
+) {
+	t.Helper()
+	if have := m.count(match); have != want {
+		t.Fatalf("gontainermock: %s called with %v %d times, want %d", method, arg, have, want)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Putter.
+// -----------------------------------------------------------------------------
+
+// PutCall is a recorded call to Put.
+type PutCall[K comparable, V any] struct {
+	Ctx context.Context
+	Key K
+	Val V
+}
+
+// Putter is a recording fake of gontainer.Putter.
+type Putter[K comparable, V any] struct {
+	// Impl answers calls when no scripted returns are left. Optional.
+	Impl func(ctx context.Context, key K, val V) (err error)
+	m    mock[PutCall[K, V], struct{}]
+}
+
+// Put implements gontainer.Putter.
+func (p *Putter[K, V]) Put(ctx context.Context, key K, val V) (err error) {
+	_, err = p.m.call(PutCall[K, V]{ctx, key, val}, func() (struct{}, error) {
+		if p.Impl == nil {
+			return struct{}{}, nil
+		}
+
+		return struct{}{}, p.Impl(ctx, key, val)
+	})
+
+	return
+}
+
+// Return scripts the result of the next call to Put which is not yet scripted.
+func (p *Putter[K, V]) Return(err error) *Putter[K, V] {
+	p.m.push(struct{}{}, err)
+	return p
+}
+
+// Calls returns the recorded calls to Put.
+func (p *Putter[K, V]) Calls() []PutCall[K, V] {
+	return p.m.recorded()
+}
+
+// Reset drops all recorded calls and scripted returns.
+func (p *Putter[K, V]) Reset() {
+	p.m.reset()
+}
+
+// AssertCalls fails "t" unless Put was called exactly "n" times.
+func (p *Putter[K, V]) AssertCalls(t testing.TB, n int) {
+	t.Helper()
+	p.m.assertCalls(t, "Put", n)
+}
+
+// AssertKeyCalls fails "t" unless Put was called with "key" exactly "n" times.
+func (p *Putter[K, V]) AssertKeyCalls(t testing.TB, key K, n int) {
+	t.Helper()
+	p.m.assertCallsWith(t, "Put", key, n, func(c PutCall[K, V]) bool { return c.Key == key })
+}
+
+// -----------------------------------------------------------------------------
+// Getter.
+// -----------------------------------------------------------------------------
+
+// GetCall is a recorded call to Get.
+type GetCall[K comparable] struct {
+	Ctx context.Context
+	Key K
+}
+
+// Getter is a recording fake of gontainer.Getter.
+type Getter[K comparable, V any] struct {
+	// Impl answers calls when no scripted returns are left. Optional.
+	Impl func(ctx context.Context, key K) (val V, err error)
+	m    mock[GetCall[K], V]
+}
+
+// Get implements gontainer.Getter.
+func (g *Getter[K, V]) Get(ctx context.Context, key K) (val V, err error) {
+	return g.m.call(GetCall[K]{ctx, key}, func() (V, error) {
+		if g.Impl == nil {
+			return *new(V), nil
+		}
+
+		return g.Impl(ctx, key)
+	})
+}
+
+// Return scripts the result of the next call to Get which is not yet scripted.
+func (g *Getter[K, V]) Return(val V, err error) *Getter[K, V] {
+	g.m.push(val, err)
+	return g
+}
+
+// Calls returns the recorded calls to Get.
+func (g *Getter[K, V]) Calls() []GetCall[K] {
+	return g.m.recorded()
+}
+
+// Reset drops all recorded calls and scripted returns.
+func (g *Getter[K, V]) Reset() {
+	g.m.reset()
+}
+
+// AssertCalls fails "t" unless Get was called exactly "n" times.
+func (g *Getter[K, V]) AssertCalls(t testing.TB, n int) {
+	t.Helper()
+	g.m.assertCalls(t, "Get", n)
+}
+
+// AssertKeyCalls fails "t" unless Get was called with "key" exactly "n" times.
+func (g *Getter[K, V]) AssertKeyCalls(t testing.TB, key K, n int) {
+	t.Helper()
+	g.m.assertCallsWith(t, "Get", key, n, func(c GetCall[K]) bool { return c.Key == key })
+}
+
+// -----------------------------------------------------------------------------
+// Modifier.
+// -----------------------------------------------------------------------------
+
+// ModCall is a recorded call to Mod.
+type ModCall[K comparable, V any] struct {
+	Ctx context.Context
+	Key K
+	Rcv func(v V) V
+}
+
+// Modifier is a recording fake of gontainer.Modifier. Note, "rcv" is only
+// called by Impl, scripted returns do not call it.
+type Modifier[K comparable, V any] struct {
+	// Impl answers calls when no scripted returns are left. Optional.
+	Impl func(ctx context.Context, key K, rcv func(v V) V) (err error)
+	m    mock[ModCall[K, V], struct{}]
+}
+
+// Mod implements gontainer.Modifier.
+func (m *Modifier[K, V]) Mod(ctx context.Context, key K, rcv func(v V) V) (err error) {
+	_, err = m.m.call(ModCall[K, V]{ctx, key, rcv}, func() (struct{}, error) {
+		if m.Impl == nil {
+			return struct{}{}, nil
+		}
+
+		return struct{}{}, m.Impl(ctx, key, rcv)
+	})
+
+	return
+}
+
+// Return scripts the result of the next call to Mod which is not yet scripted.
+func (m *Modifier[K, V]) Return(err error) *Modifier[K, V] {
+	m.m.push(struct{}{}, err)
+	return m
+}
+
+// Calls returns the recorded calls to Mod.
+func (m *Modifier[K, V]) Calls() []ModCall[K, V] {
+	return m.m.recorded()
+}
+
+// Reset drops all recorded calls and scripted returns.
+func (m *Modifier[K, V]) Reset() {
+	m.m.reset()
+}
+
+// AssertCalls fails "t" unless Mod was called exactly "n" times.
+func (m *Modifier[K, V]) AssertCalls(t testing.TB, n int) {
+	t.Helper()
+	m.m.assertCalls(t, "Mod", n)
+}
+
+// AssertKeyCalls fails "t" unless Mod was called with "key" exactly "n" times.
+func (m *Modifier[K, V]) AssertKeyCalls(t testing.TB, key K, n int) {
+	t.Helper()
+	m.m.assertCallsWith(t, "Mod", key, n, func(c ModCall[K, V]) bool { return c.Key == key })
+}
+
+// -----------------------------------------------------------------------------
+// Deleter.
+// -----------------------------------------------------------------------------
+
+// DelCall is a recorded call to Del.
+type DelCall[K comparable] struct {
+	Ctx context.Context
+	Key K
+}
+
+// Deleter is a recording fake of gontainer.Deleter.
+type Deleter[K comparable, V any] struct {
+	// Impl answers calls when no scripted returns are left. Optional.
+	Impl func(ctx context.Context, key K) (val V, err error)
+	m    mock[DelCall[K], V]
+}
+
+// Del implements gontainer.Deleter.
+func (d *Deleter[K, V]) Del(ctx context.Context, key K) (val V, err error) {
+	return d.m.call(DelCall[K]{ctx, key}, func() (V, error) {
+		if d.Impl == nil {
+			return *new(V), nil
+		}
+
+		return d.Impl(ctx, key)
+	})
+}
+
+// Return scripts the result of the next call to Del which is not yet scripted.
+func (d *Deleter[K, V]) Return(val V, err error) *Deleter[K, V] {
+	d.m.push(val, err)
+	return d
+}
+
+// Calls returns the recorded calls to Del.
+func (d *Deleter[K, V]) Calls() []DelCall[K] {
+	return d.m.recorded()
+}
+
+// Reset drops all recorded calls and scripted returns.
+func (d *Deleter[K, V]) Reset() {
+	d.m.reset()
+}
+
+// AssertCalls fails "t" unless Del was called exactly "n" times.
+func (d *Deleter[K, V]) AssertCalls(t testing.TB, n int) {
+	t.Helper()
+	d.m.assertCalls(t, "Del", n)
+}
+
+// AssertKeyCalls fails "t" unless Del was called with "key" exactly "n" times.
+func (d *Deleter[K, V]) AssertKeyCalls(t testing.TB, key K, n int) {
+	t.Helper()
+	d.m.assertCallsWith(t, "Del", key, n, func(c DelCall[K]) bool { return c.Key == key })
+}
+
+// -----------------------------------------------------------------------------
+// Sizer.
+// -----------------------------------------------------------------------------
+
+// Sizer is a recording fake of Container.Len or Container.Cap. The recorded
+// calls are the ctx of each call.
+type Sizer struct {
+	// Impl answers calls when no scripted returns are left. Optional.
+	Impl   func(ctx context.Context) (n int, err error)
+	method string
+	m      mock[context.Context, int]
+}
+
+func (s *Sizer) call(ctx context.Context) (n int, err error) {
+	return s.m.call(ctx, func() (int, error) {
+		if s.Impl == nil {
+			return 0, nil
+		}
+
+		return s.Impl(ctx)
+	})
+}
+
+// Return scripts the result of the next call which is not yet scripted.
+func (s *Sizer) Return(n int, err error) *Sizer {
+	s.m.push(n, err)
+	return s
+}
+
+// Calls returns the ctx of all recorded calls.
+func (s *Sizer) Calls() []context.Context {
+	return s.m.recorded()
+}
+
+// Reset drops all recorded calls and scripted returns.
+func (s *Sizer) Reset() {
+	s.m.reset()
+}
+
+// AssertCalls fails "t" unless it was called exactly "n" times.
+func (s *Sizer) AssertCalls(t testing.TB, n int) {
+	t.Helper()
+
+	method := s.method
+	if method == "" {
+		method = "Len/Cap"
+	}
+
+	s.m.assertCalls(t, method, n)
+}
+
+// -----------------------------------------------------------------------------
+// Container.
+// -----------------------------------------------------------------------------
+
+// Container is a recording fake of gontainer.Container. Each method is backed
+// by its own fake, e.g. c.Putter.AssertKeyCalls(t, 1, 1) checks that Put was
+// called once with the key 1.
+type Container[K comparable, V any] struct {
+	Putter[K, V]
+	Getter[K, V]
+	Modifier[K, V]
+	Deleter[K, V]
+	Lener Sizer
+	Caper Sizer
+}
+
+// NewContainer returns a Container which forwards calls to "c" when no
+// scripted returns are left. It is a spy if "c" is a real container, such as
+// one returned by gontainer.New. If "c" is nil, calls which are not scripted
+// return zero values.
+func NewContainer[K comparable, V any](c gontainer.Container[K, V]) *Container[K, V] {
+	m := &Container[K, V]{}
+	m.Lener.method = "Len"
+	m.Caper.method = "Cap"
+	if c == nil {
+		return m
+	}
+
+	m.Putter.Impl = c.Put
+	m.Getter.Impl = c.Get
+	m.Modifier.Impl = c.Mod
+	m.Deleter.Impl = c.Del
+	m.Lener.Impl = c.Len
+	m.Caper.Impl = c.Cap
+	return m
+}
+
+// Len implements gontainer.Container.Len, see Container.Lener.
+func (c *Container[K, V]) Len(ctx context.Context) (n int, err error) {
+	return c.Lener.call(ctx)
+}
+
+// Cap implements gontainer.Container.Cap, see Container.Caper.
+func (c *Container[K, V]) Cap(ctx context.Context) (n int, err error) {
+	return c.Caper.call(ctx)
+}
+
+// Reset drops all recorded calls and scripted returns of all methods.
+func (c *Container[K, V]) Reset() {
+	c.Putter.Reset()
+	c.Getter.Reset()
+	c.Modifier.Reset()
+	c.Deleter.Reset()
+	c.Lener.Reset()
+	c.Caper.Reset()
+}
+
+// -----------------------------------------------------------------------------
+// Searcher.
+// -----------------------------------------------------------------------------
+
+// SearchCall is a recorded call to Search or SearchDelete.
+type SearchCall[Q any] struct {
+	Ctx    context.Context
+	Filter Q
+}
+
+// matchFilter compares filters with reflect.DeepEqual, since Q may not be
+// comparable.
+func matchFilter[Q any](filter Q) func(SearchCall[Q]) bool {
+	return func(c SearchCall[Q]) bool { return reflect.DeepEqual(c.Filter, filter) }
+}
+
+// Searcher is a recording fake of gontainer.Searcher.
+type Searcher[Q, R any] struct {
+	// Impl answers calls when no scripted returns are left. Optional.
+	Impl func(ctx context.Context, filter Q) (r R, err error)
+	m    mock[SearchCall[Q], R]
+}
+
+// Search implements gontainer.Searcher.
+func (s *Searcher[Q, R]) Search(ctx context.Context, filter Q) (r R, err error) {
+	return s.m.call(SearchCall[Q]{ctx, filter}, func() (R, error) {
+		if s.Impl == nil {
+			return *new(R), nil
+		}
+
+		return s.Impl(ctx, filter)
+	})
+}
+
+// Return scripts the result of the next call to Search which is not yet
+// scripted.
+func (s *Searcher[Q, R]) Return(r R, err error) *Searcher[Q, R] {
+	s.m.push(r, err)
+	return s
+}
+
+// Calls returns the recorded calls to Search.
+func (s *Searcher[Q, R]) Calls() []SearchCall[Q] {
+	return s.m.recorded()
+}
+
+// Reset drops all recorded calls and scripted returns.
+func (s *Searcher[Q, R]) Reset() {
+	s.m.reset()
+}
+
+// AssertCalls fails "t" unless Search was called exactly "n" times.
+func (s *Searcher[Q, R]) AssertCalls(t testing.TB, n int) {
+	t.Helper()
+	s.m.assertCalls(t, "Search", n)
+}
+
+// AssertFilterCalls fails "t" unless Search was called with a filter which is
+// deeply equal to "filter" exactly "n" times.
+func (s *Searcher[Q, R]) AssertFilterCalls(t testing.TB, filter Q, n int) {
+	t.Helper()
+	s.m.assertCallsWith(t, "Search", filter, n, matchFilter(filter))
+}
+
+// -----------------------------------------------------------------------------
+// SearchUpdater.
+// -----------------------------------------------------------------------------
+
+// SearchUpdateCall is a recorded call to SearchUpdate.
+type SearchUpdateCall[Q, U any] struct {
+	Ctx    context.Context
+	Filter Q
+	Update U
+}
+
+// SearchUpdater is a recording fake of gontainer.SearchUpdater.
+type SearchUpdater[Q, U, R any] struct {
+	// Impl answers calls when no scripted returns are left. Optional.
+	Impl func(ctx context.Context, filter Q, update U) (r R, err error)
+	m    mock[SearchUpdateCall[Q, U], R]
+}
+
+// SearchUpdate implements gontainer.SearchUpdater.
+func (s *SearchUpdater[Q, U, R]) SearchUpdate(
+	ctx context.Context,
+	filter Q,
+	update U,
+) (
+	r R,
+	err error,
+) {
+	return s.m.call(SearchUpdateCall[Q, U]{ctx, filter, update}, func() (R, error) {
+		if s.Impl == nil {
+			return *new(R), nil
+		}
+
+		return s.Impl(ctx, filter, update)
+	})
+}
+
+// Return scripts the result of the next call to SearchUpdate which is not yet
+// scripted.
+func (s *SearchUpdater[Q, U, R]) Return(r R, err error) *SearchUpdater[Q, U, R] {
+	s.m.push(r, err)
+	return s
+}
+
+// Calls returns the recorded calls to SearchUpdate.
+func (s *SearchUpdater[Q, U, R]) Calls() []SearchUpdateCall[Q, U] {
+	return s.m.recorded()
+}
+
+// Reset drops all recorded calls and scripted returns.
+func (s *SearchUpdater[Q, U, R]) Reset() {
+	s.m.reset()
+}
+
+// AssertCalls fails "t" unless SearchUpdate was called exactly "n" times.
+func (s *SearchUpdater[Q, U, R]) AssertCalls(t testing.TB, n int) {
+	t.Helper()
+	s.m.assertCalls(t, "SearchUpdate", n)
+}
+
+// AssertFilterCalls fails "t" unless SearchUpdate was called with a filter
+// which is deeply equal to "filter" exactly "n" times.
+func (s *SearchUpdater[Q, U, R]) AssertFilterCalls(t testing.TB, filter Q, n int) {
+	t.Helper()
+	s.m.assertCallsWith(t, "SearchUpdate", filter, n, func(c SearchUpdateCall[Q, U]) bool {
+		return reflect.DeepEqual(c.Filter, filter)
+	})
+}
+
+// -----------------------------------------------------------------------------
+// SearchDeleter.
+// -----------------------------------------------------------------------------
+
+// SearchDeleter is a recording fake of gontainer.SearchDeleter.
+type SearchDeleter[Q, R any] struct {
+	// Impl answers calls when no scripted returns are left. Optional.
+	Impl func(ctx context.Context, filter Q) (r R, err error)
+	m    mock[SearchCall[Q], R]
+}
+
+// SearchDelete implements gontainer.SearchDeleter.
+func (s *SearchDeleter[Q, R]) SearchDelete(ctx context.Context, filter Q) (r R, err error) {
+	return s.m.call(SearchCall[Q]{ctx, filter}, func() (R, error) {
+		if s.Impl == nil {
+			return *new(R), nil
+		}
+
+		return s.Impl(ctx, filter)
+	})
+}
+
+// Return scripts the result of the next call to SearchDelete which is not yet
+// scripted.
+func (s *SearchDeleter[Q, R]) Return(r R, err error) *SearchDeleter[Q, R] {
+	s.m.push(r, err)
+	return s
+}
+
+// Calls returns the recorded calls to SearchDelete.
+func (s *SearchDeleter[Q, R]) Calls() []SearchCall[Q] {
+	return s.m.recorded()
+}
+
+// Reset drops all recorded calls and scripted returns.
+func (s *SearchDeleter[Q, R]) Reset() {
+	s.m.reset()
+}
+
+// AssertCalls fails "t" unless SearchDelete was called exactly "n" times.
+func (s *SearchDeleter[Q, R]) AssertCalls(t testing.TB, n int) {
+	t.Helper()
+	s.m.assertCalls(t, "SearchDelete", n)
+}
+
+// AssertFilterCalls fails "t" unless SearchDelete was called with a filter
+// which is deeply equal to "filter" exactly "n" times.
+func (s *SearchDeleter[Q, R]) AssertFilterCalls(t testing.TB, filter Q, n int) {
+	t.Helper()
+	s.m.assertCallsWith(t, "SearchDelete", filter, n, matchFilter(filter))
+}
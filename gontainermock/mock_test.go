@@ -0,0 +1,141 @@
+package gontainermock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/crunchypi/gontainer"
+)
+
+var (
+	_ gontainer.Container[int, int]          = (*Container[int, int])(nil)
+	_ gontainer.Searcher[int, int]           = (*Searcher[int, int])(nil)
+	_ gontainer.SearchUpdater[int, int, int] = (*SearchUpdater[int, int, int])(nil)
+	_ gontainer.SearchDeleter[int, int]      = (*SearchDeleter[int, int])(nil)
+)
+
+// fakeTB records failures instead of stopping the test.
+type fakeTB struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Fatalf(string, ...any) { f.failed = true }
+
+func TestPutterRecordsCalls(t *testing.T) {
+	p := &Putter[string, int]{}
+	ctx := context.Background()
+
+	p.Put(ctx, "a", 1)
+	p.Put(ctx, "b", 2)
+	p.Put(ctx, "a", 3)
+
+	calls := p.Calls()
+	if len(calls) != 3 || calls[2].Key != "a" || calls[2].Val != 3 {
+		t.Fatalf("unexpected calls: %+v", calls)
+	}
+
+	p.AssertCalls(t, 3)
+	p.AssertKeyCalls(t, "a", 2)
+	p.AssertKeyCalls(t, "b", 1)
+	p.AssertKeyCalls(t, "c", 0)
+
+	p.Reset()
+	p.AssertCalls(t, 0)
+}
+
+func TestGetterScript(t *testing.T) {
+	errX := errors.New("x")
+	g := &Getter[int, string]{}
+	g.Impl = func(context.Context, int) (string, error) { return "impl", nil }
+	g.Return("a", nil).Return("", errX)
+	ctx := context.Background()
+
+	v, err := g.Get(ctx, 1)
+	if v != "a" || err != nil {
+		t.Fatalf("first: have '%v', '%v'", v, err)
+	}
+
+	_, err = g.Get(ctx, 1)
+	if !errors.Is(err, errX) {
+		t.Fatalf("second: have '%v'", err)
+	}
+
+	// Script is exhausted, so Impl answers.
+	v, err = g.Get(ctx, 1)
+	if v != "impl" || err != nil {
+		t.Fatalf("third: have '%v', '%v'", v, err)
+	}
+
+	g.AssertKeyCalls(t, 1, 3)
+}
+
+func TestAssertFails(t *testing.T) {
+	d := &Deleter[int, int]{}
+	d.Del(context.Background(), 1)
+
+	tb := &fakeTB{}
+	d.AssertKeyCalls(tb, 1, 2)
+	if !tb.failed {
+		t.Fatal("want AssertKeyCalls to fail")
+	}
+
+	tb = &fakeTB{}
+	d.AssertCalls(tb, 1)
+	if tb.failed {
+		t.Fatal("want AssertCalls to pass")
+	}
+}
+
+func TestNewContainerSpy(t *testing.T) {
+	c := NewContainer(gontainer.New[int, int]())
+	ctx := context.Background()
+
+	c.Put(ctx, 1, 1)
+	c.Mod(ctx, 1, func(v int) int { return v + 1 })
+
+	// Scripted returns take precedence over the wrapped container.
+	c.Getter.Return(0, gontainer.ErrGet)
+	_, err := c.Get(ctx, 1)
+	if !errors.Is(err, gontainer.ErrGet) {
+		t.Fatalf("scripted get: have '%v'", err)
+	}
+
+	v, err := c.Get(ctx, 1)
+	if v != 2 || err != nil {
+		t.Fatalf("get: have '%v', '%v'", v, err)
+	}
+
+	n, _ := c.Len(ctx)
+	if n != 1 {
+		t.Fatalf("len: have '%d'", n)
+	}
+
+	c.Putter.AssertKeyCalls(t, 1, 1)
+	c.Modifier.AssertKeyCalls(t, 1, 1)
+	c.Getter.AssertCalls(t, 2)
+	c.Deleter.AssertCalls(t, 0)
+	c.Lener.AssertCalls(t, 1)
+	c.Caper.AssertCalls(t, 0)
+}
+
+func TestSearcherFilterCalls(t *testing.T) {
+	type filter struct{ Tags []string }
+
+	s := &Searcher[filter, []int]{}
+	s.Return([]int{1}, nil)
+	ctx := context.Background()
+
+	r, _ := s.Search(ctx, filter{Tags: []string{"a"}})
+	s.Search(ctx, filter{Tags: []string{"b"}})
+
+	if len(r) != 1 || r[0] != 1 {
+		t.Fatalf("search: have '%v'", r)
+	}
+
+	s.AssertFilterCalls(t, filter{Tags: []string{"a"}}, 1)
+	s.AssertFilterCalls(t, filter{Tags: []string{"c"}}, 0)
+}
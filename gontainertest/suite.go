@@ -0,0 +1,357 @@
+// Package gontainertest provides a conformance test suite for implementations
+// of gontainer.Container.
+package gontainertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/crunchypi/gontainer"
+)
+
+// Factory describes the Container which is tested by RunContainerSuite.
+type Factory[K comparable, V any] struct {
+	// New returns a new and empty Container. It is called once per sub-test.
+	New func() gontainer.Container[K, V]
+	// Key returns the i-th key. Different "i" must give different keys.
+	Key func(i int) K
+	// Val returns the i-th value. Different "i" must give different values,
+	// and none of them may be the zero value of V. Values are compared by their
+	// %#v formatting.
+	Val func(i int) V
+	// ModUpserts specifies how Mod behaves on a missing key. In both cases,
+	// Mod must return an error which wraps gontainer.ErrNotFound. If true, the
+	// callback must also be called with the zero value of V and its result
+	// stored (as gontainer.New does). If false, the key must remain missing.
+	ModUpserts bool
+	// Workers is the number of goroutines used by the concurrency tests.
+	// Defaults to 8 if zero or negative.
+	Workers int
+}
+
+// RunContainerSuite runs the conformance tests for the Container given by "f"
+// as sub-tests of "t". It checks the semantics of Put, Get, Mod, Del, Len and
+// Cap, that missing keys are reported with gontainer.ErrNotFound (along with
+// the sentinel of the operation, e.g. gontainer.ErrGet), that all methods fail
+// with an error which wraps the ctx error if the ctx is done, and that the
+// Container is safe for concurrent use (run with -race).
+func RunContainerSuite[K comparable, V any](t *testing.T, f Factory[K, V]) {
+	t.Helper()
+
+	if f.New == nil || f.Key == nil || f.Val == nil {
+		t.Fatal("gontainertest: Factory.New, Factory.Key and Factory.Val are required")
+	}
+
+	if f.Workers <= 0 {
+		f.Workers = 8
+	}
+
+	t.Run("PutGet", func(t *testing.T) { testPutGet(t, f) })
+	t.Run("PutOverwrite", func(t *testing.T) { testPutOverwrite(t, f) })
+	t.Run("GetMissing", func(t *testing.T) { testGetMissing(t, f) })
+	t.Run("Mod", func(t *testing.T) { testMod(t, f) })
+	t.Run("ModMissing", func(t *testing.T) { testModMissing(t, f) })
+	t.Run("Del", func(t *testing.T) { testDel(t, f) })
+	t.Run("DelMissing", func(t *testing.T) { testDelMissing(t, f) })
+	t.Run("LenCap", func(t *testing.T) { testLenCap(t, f) })
+	t.Run("Canceled", func(t *testing.T) { testCanceled(t, f) })
+	t.Run("ConcurrentDistinct", func(t *testing.T) { testConcurrentDistinct(t, f) })
+	t.Run("ConcurrentShared", func(t *testing.T) { testConcurrentShared(t, f) })
+}
+
+// -----------------------------------------------------------------------------
+// Helpers.
+// -----------------------------------------------------------------------------
+
+func assertNoErr(t *testing.T, subject string, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: unexpected err: %v", subject, err)
+	}
+}
+
+func assertIs(t *testing.T, subject string, err error, targets ...error) {
+	t.Helper()
+	for _, target := range targets {
+		if !errors.Is(err, target) {
+			t.Fatalf("%s: want err which wraps '%v', have '%v'", subject, target, err)
+		}
+	}
+}
+
+func assertVal[V any](t *testing.T, subject string, want, have V) {
+	t.Helper()
+
+	// V is not comparable, so compare the formatted values.
+	if w, h := fmt.Sprintf("%#v", want), fmt.Sprintf("%#v", have); w != h {
+		t.Fatalf("%s: want '%s', have '%s'", subject, w, h)
+	}
+}
+
+func assertLen[K comparable, V any](t *testing.T, c gontainer.Container[K, V], want int) {
+	t.Helper()
+
+	n, err := c.Len(context.Background())
+	assertNoErr(t, "len", err)
+	if n != want {
+		t.Fatalf("len: want '%d', have '%d'", want, n)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Tests.
+// -----------------------------------------------------------------------------
+
+func testPutGet[K comparable, V any](t *testing.T, f Factory[K, V]) {
+	c := f.New()
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		assertNoErr(t, "put", c.Put(ctx, f.Key(i), f.Val(i)))
+	}
+
+	for i := 0; i < 3; i++ {
+		v, err := c.Get(ctx, f.Key(i))
+		assertNoErr(t, "get", err)
+		assertVal(t, "get", f.Val(i), v)
+	}
+}
+
+func testPutOverwrite[K comparable, V any](t *testing.T, f Factory[K, V]) {
+	c := f.New()
+	ctx := context.Background()
+
+	assertNoErr(t, "put", c.Put(ctx, f.Key(0), f.Val(0)))
+	assertNoErr(t, "put", c.Put(ctx, f.Key(0), f.Val(1)))
+
+	v, err := c.Get(ctx, f.Key(0))
+	assertNoErr(t, "get", err)
+	assertVal(t, "get", f.Val(1), v)
+	assertLen(t, c, 1)
+}
+
+func testGetMissing[K comparable, V any](t *testing.T, f Factory[K, V]) {
+	c := f.New()
+	ctx := context.Background()
+
+	v, err := c.Get(ctx, f.Key(0))
+	assertIs(t, "get", err, gontainer.ErrNotFound, gontainer.ErrGet)
+	assertVal(t, "get", *new(V), v)
+}
+
+func testMod[K comparable, V any](t *testing.T, f Factory[K, V]) {
+	c := f.New()
+	ctx := context.Background()
+
+	assertNoErr(t, "put", c.Put(ctx, f.Key(0), f.Val(0)))
+
+	var seen V
+	err := c.Mod(ctx, f.Key(0), func(v V) V { seen = v; return f.Val(1) })
+	assertNoErr(t, "mod", err)
+	assertVal(t, "mod callback arg", f.Val(0), seen)
+
+	v, err := c.Get(ctx, f.Key(0))
+	assertNoErr(t, "get", err)
+	assertVal(t, "get", f.Val(1), v)
+}
+
+func testModMissing[K comparable, V any](t *testing.T, f Factory[K, V]) {
+	c := f.New()
+	ctx := context.Background()
+
+	called := false
+	err := c.Mod(ctx, f.Key(0), func(v V) V {
+		called = true
+		assertVal(t, "mod callback arg", *new(V), v)
+		return f.Val(0)
+	})
+	assertIs(t, "mod", err, gontainer.ErrNotFound, gontainer.ErrMod)
+
+	v, err := c.Get(ctx, f.Key(0))
+	if !f.ModUpserts {
+		assertIs(t, "get", err, gontainer.ErrNotFound)
+		assertLen(t, c, 0)
+		return
+	}
+
+	if !called {
+		t.Fatal("mod: callback was not called for a missing key")
+	}
+
+	assertNoErr(t, "get", err)
+	assertVal(t, "get", f.Val(0), v)
+	assertLen(t, c, 1)
+}
+
+func testDel[K comparable, V any](t *testing.T, f Factory[K, V]) {
+	c := f.New()
+	ctx := context.Background()
+
+	assertNoErr(t, "put", c.Put(ctx, f.Key(0), f.Val(0)))
+	assertNoErr(t, "put", c.Put(ctx, f.Key(1), f.Val(1)))
+
+	v, err := c.Del(ctx, f.Key(0))
+	assertNoErr(t, "del", err)
+	assertVal(t, "del", f.Val(0), v)
+
+	_, err = c.Get(ctx, f.Key(0))
+	assertIs(t, "get deleted", err, gontainer.ErrNotFound)
+
+	v, err = c.Get(ctx, f.Key(1))
+	assertNoErr(t, "get other", err)
+	assertVal(t, "get other", f.Val(1), v)
+	assertLen(t, c, 1)
+}
+
+func testDelMissing[K comparable, V any](t *testing.T, f Factory[K, V]) {
+	c := f.New()
+	ctx := context.Background()
+
+	v, err := c.Del(ctx, f.Key(0))
+	assertIs(t, "del", err, gontainer.ErrNotFound, gontainer.ErrDel)
+	assertVal(t, "del", *new(V), v)
+}
+
+func testLenCap[K comparable, V any](t *testing.T, f Factory[K, V]) {
+	c := f.New()
+	ctx := context.Background()
+
+	assertLen(t, c, 0)
+
+	for i := 0; i < 5; i++ {
+		assertNoErr(t, "put", c.Put(ctx, f.Key(i), f.Val(i)))
+	}
+
+	assertLen(t, c, 5)
+
+	n, err := c.Cap(ctx)
+	assertNoErr(t, "cap", err)
+	if n < 5 {
+		t.Fatalf("cap: want at least len '5', have '%d'", n)
+	}
+
+	c.Del(ctx, f.Key(0))
+	assertLen(t, c, 4)
+}
+
+func testCanceled[K comparable, V any](t *testing.T, f Factory[K, V]) {
+	c := f.New()
+	assertNoErr(t, "put", c.Put(context.Background(), f.Key(0), f.Val(0)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := c.Put(ctx, f.Key(1), f.Val(1))
+	assertIs(t, "put", err, context.Canceled)
+
+	_, err = c.Get(ctx, f.Key(0))
+	assertIs(t, "get", err, context.Canceled)
+
+	err = c.Mod(ctx, f.Key(0), func(v V) V { return v })
+	assertIs(t, "mod", err, context.Canceled)
+
+	_, err = c.Del(ctx, f.Key(0))
+	assertIs(t, "del", err, context.Canceled)
+
+	_, err = c.Len(ctx)
+	assertIs(t, "len", err, context.Canceled)
+
+	_, err = c.Cap(ctx)
+	assertIs(t, "cap", err, context.Canceled)
+
+	// Nothing was written or deleted.
+	v, err := c.Get(context.Background(), f.Key(0))
+	assertNoErr(t, "get", err)
+	assertVal(t, "get", f.Val(0), v)
+	assertLen(t, c, 1)
+}
+
+// testConcurrentDistinct has each worker write its own keys, and checks that
+// no write is lost.
+func testConcurrentDistinct[K comparable, V any](t *testing.T, f Factory[K, V]) {
+	c := f.New()
+	ctx := context.Background()
+
+	const perWorker = 100
+	errs := make(chan error, f.Workers)
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < f.Workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w * perWorker; i < (w+1)*perWorker; i++ {
+				if err := c.Put(ctx, f.Key(i), f.Val(i)); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(w)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assertNoErr(t, "put", err)
+	}
+
+	assertLen(t, c, f.Workers*perWorker)
+	for i := 0; i < f.Workers*perWorker; i++ {
+		v, err := c.Get(ctx, f.Key(i))
+		assertNoErr(t, "get", err)
+		assertVal(t, "get", f.Val(i), v)
+	}
+}
+
+// testConcurrentShared has all workers call all methods on the same few keys.
+// Only errors which are not ErrNotFound fail the test, the main purpose is to
+// be run with the race detector.
+func testConcurrentShared[K comparable, V any](t *testing.T, f Factory[K, V]) {
+	c := f.New()
+	ctx := context.Background()
+
+	const keys = 4
+	const iters = 200
+	errs := make(chan error, f.Workers)
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < f.Workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iters; i++ {
+				k := f.Key((w + i) % keys)
+
+				var err error
+				switch i % 6 {
+				case 0:
+					err = c.Put(ctx, k, f.Val(i))
+				case 1:
+					_, err = c.Get(ctx, k)
+				case 2:
+					err = c.Mod(ctx, k, func(V) V { return f.Val(i) })
+				case 3:
+					_, err = c.Del(ctx, k)
+				case 4:
+					_, err = c.Len(ctx)
+				case 5:
+					_, err = c.Cap(ctx)
+				}
+
+				if err != nil && !errors.Is(err, gontainer.ErrNotFound) {
+					errs <- err
+					return
+				}
+			}
+		}(w)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assertNoErr(t, "concurrent", err)
+	}
+}
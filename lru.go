@@ -0,0 +1,271 @@
+package gontainer
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// lruEntry is the value of each element in lruWrap.list.
+type lruEntry[K comparable, V any] struct {
+	key K
+	val V
+}
+
+// lruWrap is a bounded container which evicts the least-recently-used entry
+// when full. The front of the list is the most recently used entry.
+type lruWrap[K comparable, V any] struct {
+	mu      sync.Mutex
+	cap     int
+	list    *list.List
+	items   map[K]*list.Element
+	onEvict func(key K, val V)
+}
+
+// NewLRU returns an in-memory container which holds at most "capacity"
+// entries. When a new key is added to a full container, the least-recently-used
+// entry is evicted and passed to "onEvict" (if not nil), which may be used to write
+// evicted values through to a slower store. Put, Get and Mod count as a use.
+//
+// Notes:
+//   - "capacity" is clamped to 1 if it is smaller, and Cap always returns it.
+//   - Like New, Mod will run the callback and save the result even if the
+//     key does not exist. This counts as adding a key, so it may evict.
+//   - "onEvict" is called after the container is unlocked, in eviction order,
+//     so it may safely call back into the container.
+//   - All methods fail with an OpError which wraps the ctx error if the ctx
+//     is done.
+//   - It implements Swapper as well, so SwapperFor does not need to probe
+//     missing keys with Mod.
+func NewLRU[K comparable, V any](capacity int, onEvict func(key K, val V)) Container[K, V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &lruWrap[K, V]{
+		cap:     capacity,
+		list:    list.New(),
+		items:   make(map[K]*list.Element, capacity),
+		onEvict: onEvict,
+	}
+}
+
+// set adds or updates "k" as the most recently used entry, and returns any
+// entry which had to be evicted to make room. Must be called while locked.
+func (l *lruWrap[K, V]) set(k K, v V) (evicted []lruEntry[K, V]) {
+	if e, ok := l.items[k]; ok {
+		e.Value.(*lruEntry[K, V]).val = v
+		l.list.MoveToFront(e)
+		return
+	}
+
+	for l.list.Len() >= l.cap {
+		e := l.list.Back()
+		entry := l.list.Remove(e).(*lruEntry[K, V])
+		delete(l.items, entry.key)
+		evicted = append(evicted, *entry)
+	}
+
+	l.items[k] = l.list.PushFront(&lruEntry[K, V]{key: k, val: v})
+	return
+}
+
+// evict passes each entry to the eviction callback. Must be called unlocked.
+func (l *lruWrap[K, V]) evict(entries []lruEntry[K, V]) {
+	if l.onEvict == nil {
+		return
+	}
+
+	for _, entry := range entries {
+		l.onEvict(entry.key, entry.val)
+	}
+}
+
+// Put implements Putter.
+func (l *lruWrap[K, V]) Put(ctx context.Context, k K, v V) (err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpPut, Key: k, Err: err}
+		return
+	}
+
+	l.mu.Lock()
+	evicted := l.set(k, v)
+	l.mu.Unlock()
+
+	l.evict(evicted)
+	return
+}
+
+// Get implements Getter.
+func (l *lruWrap[K, V]) Get(ctx context.Context, k K) (v V, err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpGet, Key: k, Err: err}
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.items[k]
+	if !ok {
+		err = &OpError{Op: OpGet, Key: k, Err: ErrNotFound}
+		return
+	}
+
+	l.list.MoveToFront(e)
+	v = e.Value.(*lruEntry[K, V]).val
+	return
+}
+
+// Mod implements Modifier. Note, will still do a write if "k" is not found,
+// but the returned error wraps ErrNotFound.
+// The callback is called while the container is locked, so it must not call
+// back into the container.
+func (l *lruWrap[K, V]) Mod(ctx context.Context, k K, f func(V) V) (err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpMod, Key: k, Err: err}
+		return
+	}
+
+	if f == nil {
+		return
+	}
+
+	evicted, err := l.mod(k, f)
+	l.evict(evicted)
+	return
+}
+
+// mod applies "f" to the value of "k", or adds it if missing. The lock is
+// released with defer, since "f" is user code which may panic.
+func (l *lruWrap[K, V]) mod(k K, f func(V) V) (evicted []lruEntry[K, V], err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	v := *new(V)
+	if e, ok := l.items[k]; ok {
+		v = e.Value.(*lruEntry[K, V]).val
+	} else {
+		err = &OpError{Op: OpMod, Key: k, Err: ErrNotFound}
+	}
+
+	return l.set(k, f(v)), err
+}
+
+// Del implements Deleter. Deleted entries are not passed to the eviction
+// callback.
+func (l *lruWrap[K, V]) Del(ctx context.Context, k K) (v V, err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpDel, Key: k, Err: err}
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.items[k]
+	if !ok {
+		err = &OpError{Op: OpDel, Key: k, Err: ErrNotFound}
+		return
+	}
+
+	delete(l.items, k)
+	v = l.list.Remove(e).(*lruEntry[K, V]).val
+	return
+}
+
+// PutIfAbsent implements Swapper. Adding the key counts as a use, and may
+// evict.
+func (l *lruWrap[K, V]) PutIfAbsent(ctx context.Context, k K, v V) (err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpPut, Key: k, Err: err}
+		return
+	}
+
+	l.mu.Lock()
+	if _, ok := l.items[k]; ok {
+		l.mu.Unlock()
+		err = &OpError{Op: OpPut, Key: k, Err: ErrExists}
+		return
+	}
+
+	evicted := l.set(k, v)
+	l.mu.Unlock()
+
+	l.evict(evicted)
+	return
+}
+
+// CompareAndSwap implements Swapper. A swap counts as a use.
+func (l *lruWrap[K, V]) CompareAndSwap(ctx context.Context, k K, old, new V) (err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpMod, Key: k, Err: err}
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.items[k]
+	switch {
+	case !ok:
+		err = &OpError{Op: OpMod, Key: k, Err: ErrNotFound}
+	case !equal(e.Value.(*lruEntry[K, V]).val, old):
+		err = &OpError{Op: OpMod, Key: k, Err: ErrConflict}
+	default:
+		e.Value.(*lruEntry[K, V]).val = new
+		l.list.MoveToFront(e)
+	}
+
+	return
+}
+
+// CompareAndDelete implements Swapper. Deleted entries are not passed to the
+// eviction callback.
+func (l *lruWrap[K, V]) CompareAndDelete(ctx context.Context, k K, old V) (err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpDel, Key: k, Err: err}
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.items[k]
+	switch {
+	case !ok:
+		err = &OpError{Op: OpDel, Key: k, Err: ErrNotFound}
+	case !equal(e.Value.(*lruEntry[K, V]).val, old):
+		err = &OpError{Op: OpDel, Key: k, Err: ErrConflict}
+	default:
+		delete(l.items, k)
+		l.list.Remove(e)
+	}
+
+	return
+}
+
+// Len implements Container.Len.
+func (l *lruWrap[K, V]) Len(ctx context.Context) (n int, err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpLen, Err: err}
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n = l.list.Len()
+	return
+}
+
+// Cap implements Container.Cap by returning the fixed capacity.
+func (l *lruWrap[K, V]) Cap(ctx context.Context) (n int, err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpCap, Err: err}
+		return
+	}
+
+	n = l.cap
+	return
+}
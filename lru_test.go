@@ -0,0 +1,158 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Tests for NewLRU.
+// -----------------------------------------------------------------------------
+
+func TestNewLRUEvictionOrder(t *testing.T) {
+	evicted := []int{}
+	cnt := NewLRU[int, int](3, func(k, v int) { evicted = append(evicted, k) })
+	ctx := context.Background()
+
+	cnt.Put(ctx, 1, 1)
+	cnt.Put(ctx, 2, 2)
+	cnt.Put(ctx, 3, 3)
+
+	// Use 1 and 2, so 3 becomes the least recently used.
+	cnt.Get(ctx, 1)
+	cnt.Mod(ctx, 2, func(v int) int { return v })
+
+	cnt.Put(ctx, 4, 4)
+	cnt.Put(ctx, 5, 5)
+	assertEq("evicted", []int{3, 1}, evicted, func(s string) { t.Fatal(s) })
+
+	_, err := cnt.Get(ctx, 3)
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+
+	l, _ := cnt.Len(ctx)
+	assertEq("len", 3, l, func(s string) { t.Fatal(s) })
+}
+
+func TestNewLRUEvictCallback(t *testing.T) {
+	store := New[int, int]()
+	ctx := context.Background()
+
+	// The callback may call back into containers, including the LRU itself.
+	cnt := NewLRU[int, int](1, func(k, v int) { store.Put(ctx, k, v) })
+	cnt.Put(ctx, 1, 10)
+	cnt.Put(ctx, 2, 20)
+
+	val, err := store.Get(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 10, val, func(s string) { t.Fatal(s) })
+}
+
+func TestNewLRUPutExisting(t *testing.T) {
+	evicted := []int{}
+	cnt := NewLRU[int, int](2, func(k, v int) { evicted = append(evicted, k) })
+	ctx := context.Background()
+
+	cnt.Put(ctx, 1, 1)
+	cnt.Put(ctx, 2, 2)
+	cnt.Put(ctx, 1, 11)
+	assertEq("evicted", []int{}, evicted, func(s string) { t.Fatal(s) })
+
+	cnt.Put(ctx, 3, 3)
+	assertEq("evicted", []int{2}, evicted, func(s string) { t.Fatal(s) })
+
+	val, _ := cnt.Get(ctx, 1)
+	assertEq("val", 11, val, func(s string) { t.Fatal(s) })
+}
+
+func TestNewLRUDel(t *testing.T) {
+	evicted := []int{}
+	cnt := NewLRU[int, int](2, func(k, v int) { evicted = append(evicted, k) })
+	ctx := context.Background()
+
+	_, err := cnt.Del(ctx, 1)
+	assertEq("err", true, errors.Is(err, ErrDel), func(s string) { t.Fatal(s) })
+
+	cnt.Put(ctx, 1, 1)
+	val, err := cnt.Del(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 1, val, func(s string) { t.Fatal(s) })
+	assertEq("evicted", []int{}, evicted, func(s string) { t.Fatal(s) })
+}
+
+func TestNewLRUModMissing(t *testing.T) {
+	cnt := NewLRU[int, int](1, nil)
+	ctx := context.Background()
+
+	err := cnt.Mod(ctx, 1, func(v int) int { return v + 1 })
+	assertEq("err", true, errors.Is(err, ErrMod), func(s string) { t.Fatal(s) })
+
+	val, err := cnt.Get(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 1, val, func(s string) { t.Fatal(s) })
+}
+
+func TestNewLRUModPanic(t *testing.T) {
+	cnt := NewLRU[int, int](1, nil)
+	ctx := context.Background()
+
+	func() {
+		defer func() { recover() }()
+		cnt.Mod(ctx, 1, func(int) int { panic("mod") })
+	}()
+
+	// The panic must not leave the LRU locked.
+	within(t, func() {
+		n, err := cnt.Len(ctx)
+		assertEq("err", *new(error), err, func(s string) { t.Error(s) })
+		assertEq("len", 0, n, func(s string) { t.Error(s) })
+	})
+}
+
+func TestNewLRUCap(t *testing.T) {
+	cnt := NewLRU[int, int](5, nil)
+	ctx := context.Background()
+
+	c, err := cnt.Cap(ctx)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("cap", 5, c, func(s string) { t.Fatal(s) })
+
+	for i := 0; i < 10; i++ {
+		cnt.Put(ctx, i, i)
+	}
+
+	l, _ := cnt.Len(ctx)
+	assertEq("len", 5, l, func(s string) { t.Fatal(s) })
+
+	c, _ = cnt.Cap(ctx)
+	assertEq("cap", 5, c, func(s string) { t.Fatal(s) })
+}
+
+func TestNewLRUConcurrent(t *testing.T) {
+	cnt := NewLRU[int, int](8, func(int, int) {})
+	ctx := context.Background()
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				k := (w + i) % 16
+				cnt.Put(ctx, k, i)
+				cnt.Get(ctx, k)
+				cnt.Mod(ctx, k, func(v int) int { return v + 1 })
+				cnt.Del(ctx, k)
+				cnt.Len(ctx)
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	l, _ := cnt.Len(ctx)
+	if l > 8 {
+		t.Fatalf("len exceeds cap: %v", l)
+	}
+}
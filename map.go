@@ -1,62 +1,340 @@
 package gontainer
 
-import "context"
+import (
+	"context"
+	"iter"
+	"sync"
+)
 
-type mapWrap[K comparable, V any] map[K]V
+// mapWrap is the default container. It is a map[K]V guarded by a RWMutex, so
+// all methods are safe for concurrent use. All methods fail with an OpError
+// which wraps the ctx error if the ctx is done.
+type mapWrap[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  map[K]V
+}
+
+func newMapWrap[K comparable, V any]() *mapWrap[K, V] {
+	return &mapWrap[K, V]{m: make(map[K]V)}
+}
 
 // Put implements Putter.
-func (m mapWrap[K, V]) Put(ctx context.Context, k K, v V) (err error) {
-	m[k] = v
+func (m *mapWrap[K, V]) Put(ctx context.Context, k K, v V) (err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpPut, Key: k, Err: err}
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.m[k] = v
 	return
 }
 
 // Get implements Getter.
-func (m mapWrap[K, V]) Get(ctx context.Context, k K) (v V, err error) {
-	v, ok := m[k]
+func (m *mapWrap[K, V]) Get(ctx context.Context, k K) (v V, err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpGet, Key: k, Err: err}
+		return
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	v, ok := m.m[k]
 	if !ok {
-		err = ErrGet
+		err = &OpError{Op: OpGet, Key: k, Err: ErrNotFound}
 	}
 
 	return
 }
 
-// Mod implements Modifier. Note, will still do a write if "k" is not found.
-func (m mapWrap[K, V]) Mod(ctx context.Context, k K, f func(V) V) (err error) {
+// Mod implements Modifier. Note, will still do a write if "k" is not found,
+// but the returned error wraps ErrNotFound.
+// The callback is called while the container is locked, so the read-modify-
+// write is atomic with regard to other writers. As a consequence, the callback
+// must not call back into the container.
+func (m *mapWrap[K, V]) Mod(ctx context.Context, k K, f func(V) V) (err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpMod, Key: k, Err: err}
+		return
+	}
+
 	if f == nil {
 		return
 	}
 
-	v, ok := m[k]
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v, ok := m.m[k]
 	if !ok {
-		err = ErrMod
+		err = &OpError{Op: OpMod, Key: k, Err: ErrNotFound}
 	}
 
-	m[k] = f(v)
+	m.m[k] = f(v)
 	return
 }
 
 // Del implements Deleter.
-func (m mapWrap[K, V]) Del(ctx context.Context, k K) (v V, err error) {
-	v, ok := m[k]
+func (m *mapWrap[K, V]) Del(ctx context.Context, k K) (v V, err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpDel, Key: k, Err: err}
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v, ok := m.m[k]
 	if !ok {
-		err = ErrDel
+		err = &OpError{Op: OpDel, Key: k, Err: ErrNotFound}
 		return
 	}
 
-	delete(m, k)
+	delete(m.m, k)
 	return
 }
 
 // Len implements Container.Len.
-func (m mapWrap[K, V]) Len(context.Context) (n int, err error) {
-	n = len(m)
+func (m *mapWrap[K, V]) Len(ctx context.Context) (n int, err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpLen, Err: err}
+		return
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	n = len(m.m)
 	return
 }
 
 // Cap implements Container.Cap. Note, will return the double of mapWrap.Len
 // because the cap(map[K]V) is not supported, and we want to signal that there
 // is 'always' more room in this container.
-func (m mapWrap[K, V]) Cap(context.Context) (n int, err error) {
-	n = len(m) * 2
+func (m *mapWrap[K, V]) Cap(ctx context.Context) (n int, err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpCap, Err: err}
+		return
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	n = len(m.m) * 2
+	return
+}
+
+// All implements Ranger. Iteration is over a snapshot which is taken when it
+// starts, so the loop body may call back into the container.
+func (m *mapWrap[K, V]) All(ctx context.Context) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.mu.RLock()
+		snapshot := make(map[K]V, len(m.m))
+		for k, v := range m.m {
+			snapshot[k] = v
+		}
+		m.mu.RUnlock()
+
+		for k, v := range snapshot {
+			if ctx.Err() != nil || !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Keys implements Ranger, see mapWrap.All.
+func (m *mapWrap[K, V]) Keys(ctx context.Context) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		m.mu.RLock()
+		snapshot := make([]K, 0, len(m.m))
+		for k := range m.m {
+			snapshot = append(snapshot, k)
+		}
+		m.mu.RUnlock()
+
+		for _, k := range snapshot {
+			if ctx.Err() != nil || !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Page implements Pager, in ascending key order. It fails with ErrImpl unless
+// the underlying type of K is a number or a string. Note, each page scans all
+// keys (but only sorts the page), so it is O(n log limit) per page.
+func (m *mapWrap[K, V]) Page(
+	ctx context.Context,
+	cursor string,
+	limit int,
+) (
+	items []Entry[K, V],
+	next string,
+	err error,
+) {
+	return pageByKey(ctx, cursor, limit, m.scan)
+}
+
+// scan calls "visit" for each entry while the container is read-locked.
+func (m *mapWrap[K, V]) scan(visit func(k K, v V)) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for k, v := range m.m {
+		visit(k, v)
+	}
+}
+
+// PutMany implements BatchPutter. The batch is applied while the container is
+// locked, so it is atomic with regard to other callers.
+func (m *mapWrap[K, V]) PutMany(
+	ctx context.Context,
+	entries []Entry[K, V],
+) (
+	results []Result[K, V],
+	err error,
+) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpPut, Err: err}
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	results = make([]Result[K, V], len(entries))
+	for i, e := range entries {
+		m.m[e.Key] = e.Val
+		results[i].Key = e.Key
+	}
+
+	return
+}
+
+// GetMany implements BatchGetter, see mapWrap.PutMany.
+func (m *mapWrap[K, V]) GetMany(
+	ctx context.Context,
+	keys []K,
+) (
+	results []Result[K, V],
+	err error,
+) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpGet, Err: err}
+		return
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	results = make([]Result[K, V], len(keys))
+	for i, k := range keys {
+		v, ok := m.m[k]
+		results[i] = Result[K, V]{Key: k, Val: v}
+		if !ok {
+			results[i].Err = &OpError{Op: OpGet, Key: k, Err: ErrNotFound}
+		}
+	}
+
+	return
+}
+
+// DelMany implements BatchDeleter, see mapWrap.PutMany.
+func (m *mapWrap[K, V]) DelMany(
+	ctx context.Context,
+	keys []K,
+) (
+	results []Result[K, V],
+	err error,
+) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpDel, Err: err}
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	results = make([]Result[K, V], len(keys))
+	for i, k := range keys {
+		v, ok := m.m[k]
+		results[i] = Result[K, V]{Key: k, Val: v}
+		if !ok {
+			results[i].Err = &OpError{Op: OpDel, Key: k, Err: ErrNotFound}
+			continue
+		}
+
+		delete(m.m, k)
+	}
+
+	return
+}
+
+// PutIfAbsent implements Swapper.
+func (m *mapWrap[K, V]) PutIfAbsent(ctx context.Context, k K, v V) (err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpPut, Key: k, Err: err}
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.m[k]; ok {
+		err = &OpError{Op: OpPut, Key: k, Err: ErrExists}
+		return
+	}
+
+	m.m[k] = v
+	return
+}
+
+// CompareAndSwap implements Swapper.
+func (m *mapWrap[K, V]) CompareAndSwap(ctx context.Context, k K, old, new V) (err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpMod, Key: k, Err: err}
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v, ok := m.m[k]
+	switch {
+	case !ok:
+		err = &OpError{Op: OpMod, Key: k, Err: ErrNotFound}
+	case !equal(v, old):
+		err = &OpError{Op: OpMod, Key: k, Err: ErrConflict}
+	default:
+		m.m[k] = new
+	}
+
+	return
+}
+
+// CompareAndDelete implements Swapper.
+func (m *mapWrap[K, V]) CompareAndDelete(ctx context.Context, k K, old V) (err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpDel, Key: k, Err: err}
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v, ok := m.m[k]
+	switch {
+	case !ok:
+		err = &OpError{Op: OpDel, Key: k, Err: ErrNotFound}
+	case !equal(v, old):
+		err = &OpError{Op: OpDel, Key: k, Err: ErrConflict}
+	default:
+		delete(m.m, k)
+	}
+
 	return
 }
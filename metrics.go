@@ -0,0 +1,190 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// sentinels are the errors which MetricsDecorator breaks errors down by. More
+// specific errors come first, since an error may match several.
+var sentinels = []error{
+	ErrImpl,
+	ErrOpen,
+	ErrRateLimited,
+	ErrReadOnly,
+	ErrWriteOnly,
+	ErrInvalid,
+	ErrFault,
+	ErrNotFound,
+	ErrExists,
+	ErrConflict,
+	ErrPut,
+	ErrGet,
+	ErrMod,
+	ErrDel,
+	ErrSearcher,
+	ErrSearchUpdater,
+	ErrSearchDeleter,
+}
+
+// sentinelOf returns the first sentinel which "err" matches, or nil if none.
+func sentinelOf(err error) error {
+	for _, sentinel := range sentinels {
+		if errors.Is(err, sentinel) {
+			return sentinel
+		}
+	}
+
+	return nil
+}
+
+// MetricsRecorder receives measurements from MetricsDecorator. It is meant to
+// be implemented as a thin adapter over a metrics library.
+type MetricsRecorder interface {
+	// Call is called once for each call to an operation.
+	Call(op Op)
+	// Error is called for each call which failed. The "sentinel" is the first
+	// of this package's errors (ErrPut, ErrGet, ErrImpl, etc) which the error
+	// matches with errors.Is, or nil if it matches none.
+	Error(op Op, sentinel error)
+	// Latency is called for each call with how long it took.
+	Latency(op Op, d time.Duration)
+}
+
+// MetricsConfig is used to configure MetricsDecorator.
+type MetricsConfig struct {
+	// Recorder receives the measurements. Nothing is recorded if nil.
+	Recorder MetricsRecorder
+	// Clock is used to measure latency. Defaults to the wall clock if nil.
+	Clock Clock
+}
+
+// MetricsDecorator returns a Decorator which records call counts, errors and
+// latencies of each call with MetricsConfig.Recorder. The error of the call is
+// returned unchanged.
+func MetricsDecorator(cfg MetricsConfig) Decorator {
+	clock := clockOr(cfg.Clock)
+
+	return func(
+		ctx context.Context,
+		op Op,
+		key any,
+		call func(ctx context.Context) error,
+	) (
+		err error,
+	) {
+		if cfg.Recorder == nil {
+			return call(ctx)
+		}
+
+		start := clock.Now()
+		err = call(ctx)
+
+		cfg.Recorder.Call(op)
+		cfg.Recorder.Latency(op, clock.Now().Sub(start))
+		if err != nil {
+			cfg.Recorder.Error(op, sentinelOf(err))
+		}
+
+		return
+	}
+}
+
+// -----------------------------------------------------------------------------
+// MemRecorder.
+// -----------------------------------------------------------------------------
+
+// MemRecorder is an in-memory MetricsRecorder, mainly intended for testing.
+// The zero value is ready to use, and it is safe for concurrent use.
+type MemRecorder struct {
+	mu        sync.Mutex
+	calls     map[Op]int
+	errs      map[Op]map[error]int
+	latencies map[Op][]time.Duration
+}
+
+// Call implements MetricsRecorder.Call.
+func (r *MemRecorder) Call(op Op) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.calls == nil {
+		r.calls = make(map[Op]int)
+	}
+
+	r.calls[op]++
+}
+
+// Error implements MetricsRecorder.Error.
+func (r *MemRecorder) Error(op Op, sentinel error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.errs == nil {
+		r.errs = make(map[Op]map[error]int)
+	}
+	if r.errs[op] == nil {
+		r.errs[op] = make(map[error]int)
+	}
+
+	r.errs[op][sentinel]++
+}
+
+// Latency implements MetricsRecorder.Latency.
+func (r *MemRecorder) Latency(op Op, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.latencies == nil {
+		r.latencies = make(map[Op][]time.Duration)
+	}
+
+	r.latencies[op] = append(r.latencies[op], d)
+}
+
+// Calls returns the number of calls recorded for "op".
+func (r *MemRecorder) Calls(op Op) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.calls[op]
+}
+
+// Errors returns the number of errors recorded for "op" with "sentinel". Use
+// a nil "sentinel" for errors which matched none of the sentinels.
+func (r *MemRecorder) Errors(op Op, sentinel error) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.errs[op][sentinel]
+}
+
+// Latencies returns a copy of all latencies recorded for "op", in order.
+func (r *MemRecorder) Latencies(op Op) []time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]time.Duration{}, r.latencies[op]...)
+}
+
+// Histogram buckets the latencies recorded for "op" by the ascending upper
+// "bounds" (inclusive). The result has one more bucket than there are bounds,
+// the last one counts latencies above all bounds.
+func (r *MemRecorder) Histogram(op Op, bounds []time.Duration) []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	buckets := make([]int, len(bounds)+1)
+	for _, d := range r.latencies[op] {
+		i := 0
+		for i < len(bounds) && d > bounds[i] {
+			i++
+		}
+
+		buckets[i]++
+	}
+
+	return buckets
+}
@@ -0,0 +1,94 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Tests for MetricsDecorator.
+// -----------------------------------------------------------------------------
+
+func TestMetricsDecoratorContainer(t *testing.T) {
+	r := &MemRecorder{}
+	cnt := DecorateContainer(New[int, int](), MetricsDecorator(MetricsConfig{Recorder: r}))
+	ctx := context.Background()
+
+	cnt.Put(ctx, 1, 1)
+	cnt.Get(ctx, 1)
+	cnt.Get(ctx, 2)
+	cnt.Mod(ctx, 1, func(v int) int { return v })
+	cnt.Del(ctx, 3)
+	cnt.Len(ctx)
+	cnt.Cap(ctx)
+
+	assertEq("put calls", 1, r.Calls(OpPut), func(s string) { t.Fatal(s) })
+	assertEq("get calls", 2, r.Calls(OpGet), func(s string) { t.Fatal(s) })
+	assertEq("mod calls", 1, r.Calls(OpMod), func(s string) { t.Fatal(s) })
+	assertEq("del calls", 1, r.Calls(OpDel), func(s string) { t.Fatal(s) })
+	assertEq("len calls", 1, r.Calls(OpLen), func(s string) { t.Fatal(s) })
+	assertEq("cap calls", 1, r.Calls(OpCap), func(s string) { t.Fatal(s) })
+
+	assertEq("get errs", 1, r.Errors(OpGet, ErrNotFound), func(s string) { t.Fatal(s) })
+	assertEq("del errs", 1, r.Errors(OpDel, ErrNotFound), func(s string) { t.Fatal(s) })
+	assertEq("put errs", 0, r.Errors(OpPut, ErrPut), func(s string) { t.Fatal(s) })
+}
+
+func TestMetricsDecoratorSearchers(t *testing.T) {
+	r := &MemRecorder{}
+	d := MetricsDecorator(MetricsConfig{Recorder: r})
+	ctx := context.Background()
+	other := errors.New("other")
+
+	s := SearcherImpl[int, int]{}
+	s.Impl = func(context.Context, int) (int, error) { return 0, other }
+	DecorateSearcher[int, int](s, d).Search(ctx, 0)
+
+	su := SearchUpdaterImpl[int, int, int]{}
+	su.Impl = func(context.Context, int, int) (int, error) { return 0, ErrSearchUpdater }
+	DecorateSearchUpdater[int, int, int](su, d).SearchUpdate(ctx, 0, 0)
+
+	sd := SearchDeleterImpl[int, int]{}
+	DecorateSearchDeleter[int, int](sd, d).SearchDelete(ctx, 0)
+
+	assertEq("calls", 1, r.Calls(OpSearch), func(s string) { t.Fatal(s) })
+	assertEq("errs", 1, r.Errors(OpSearch, nil), func(s string) { t.Fatal(s) })
+
+	assertEq("calls", 1, r.Calls(OpSearchUpdate), func(s string) { t.Fatal(s) })
+	assertEq("errs", 1, r.Errors(OpSearchUpdate, ErrSearchUpdater), func(s string) { t.Fatal(s) })
+
+	assertEq("calls", 1, r.Calls(OpSearchDelete), func(s string) { t.Fatal(s) })
+	assertEq("errs", 1, r.Errors(OpSearchDelete, ErrImpl), func(s string) { t.Fatal(s) })
+}
+
+func TestMetricsDecoratorLatency(t *testing.T) {
+	r := &MemRecorder{}
+	clock := newFakeClock()
+	d := MetricsDecorator(MetricsConfig{Recorder: r, Clock: clock})
+
+	latencies := []time.Duration{time.Millisecond, 5 * time.Millisecond, time.Second}
+	g := GetterImpl[int, time.Duration]{}
+	g.Impl = func(_ context.Context, i int) (time.Duration, error) {
+		clock.Advance(latencies[i])
+		return latencies[i], nil
+	}
+
+	dg := DecorateGetter[int, time.Duration](g, d)
+	for i := range latencies {
+		dg.Get(context.Background(), i)
+	}
+
+	assertEq("latencies", latencies, r.Latencies(OpGet), func(s string) { t.Fatal(s) })
+
+	bounds := []time.Duration{time.Millisecond, 10 * time.Millisecond}
+	assertEq("histogram", []int{1, 1, 1}, r.Histogram(OpGet, bounds), func(s string) { t.Fatal(s) })
+}
+
+func TestMetricsDecoratorNilRecorder(t *testing.T) {
+	g := DecorateGetter[int, int](New[int, int](), MetricsDecorator(MetricsConfig{}))
+
+	_, err := g.Get(context.Background(), 1)
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+}
@@ -0,0 +1,220 @@
+package gontainer
+
+import (
+	"cmp"
+	"container/heap"
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+)
+
+// Entry is a key-value pair.
+type Entry[K comparable, V any] struct {
+	Key K
+	Val V
+}
+
+// -----------------------------------------------------------------------------
+// Pager.
+// -----------------------------------------------------------------------------
+
+// Pager represents something which lists its contents in pages. It is
+// optional, and not part of Container.
+//
+// Page returns up to "limit" entries which come after "cursor", along with
+// the cursor of the next page. The empty cursor is the start, and an empty
+// "next" means that there are no more pages. Cursors are opaque, and are only
+// valid for the Pager which returned them. Entries are in a stable order, so
+// paging through a container which is not modified meanwhile sees each entry
+// exactly once. Entries which are added or removed while paging may or may not
+// be seen.
+type Pager[K comparable, V any] interface {
+	Page(
+		ctx context.Context,
+		cursor string,
+		limit int,
+	) (
+		items []Entry[K, V],
+		next string,
+		err error,
+	)
+}
+
+// PagerImpl lets you implement Pager with a function. The call to Page is
+// simply forwarded to the internal function "Impl".
+type PagerImpl[K comparable, V any] struct {
+	Impl func(ctx context.Context, cursor string, limit int) (items []Entry[K, V], next string, err error)
+}
+
+// Page implements Pager.Page by forwarding the call to the internal "Impl".
+func (impl PagerImpl[K, V]) Page(
+	ctx context.Context,
+	cursor string,
+	limit int,
+) (
+	items []Entry[K, V],
+	next string,
+	err error,
+) {
+	if impl.Impl == nil {
+		err = ErrImpl
+		return
+	}
+
+	return impl.Impl(ctx, cursor, limit)
+}
+
+// Paginate returns an iterator over all entries of "p", which fetches pages
+// of "limit" entries as needed. If a page fails, its error is yielded with the
+// zero Entry, and iteration stops.
+func Paginate[K comparable, V any](
+	ctx context.Context,
+	p Pager[K, V],
+	limit int,
+) iter.Seq2[Entry[K, V], error] {
+	return func(yield func(Entry[K, V], error) bool) {
+		cursor := ""
+		for {
+			items, next, err := p.Page(ctx, cursor, limit)
+			if err != nil {
+				yield(Entry[K, V]{}, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if next == "" {
+				return
+			}
+
+			cursor = next
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Key-ordered paging, used by the in-memory containers.
+// -----------------------------------------------------------------------------
+
+// ordered reports whether keys of type K can be ordered by keyCmp, i.e. if the
+// underlying type of K is a number or a string.
+func ordered[K comparable]() bool {
+	switch reflect.TypeFor[K]().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.String:
+		return true
+	}
+
+	return false
+}
+
+// keyCmp compares keys for which ordered is true.
+func keyCmp[K comparable](a, b K) int {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case va.CanInt():
+		return cmp.Compare(va.Int(), vb.Int())
+	case va.CanUint():
+		return cmp.Compare(va.Uint(), vb.Uint())
+	case va.CanFloat():
+		return cmp.Compare(va.Float(), vb.Float())
+	default:
+		return cmp.Compare(va.String(), vb.String())
+	}
+}
+
+// pageHeap is a max-heap of entries by key, used to keep the smallest keys.
+type pageHeap[K comparable, V any] []Entry[K, V]
+
+func (h pageHeap[K, V]) Len() int           { return len(h) }
+func (h pageHeap[K, V]) Less(i, j int) bool { return keyCmp(h[i].Key, h[j].Key) > 0 }
+func (h pageHeap[K, V]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *pageHeap[K, V]) Push(x any)        { *h = append(*h, x.(Entry[K, V])) }
+func (h *pageHeap[K, V]) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// pageByKey implements Pager.Page in ascending key order, with the JSON of the
+// last key as cursor. "scan" must call "visit" for all entries, in any order.
+// Only the "limit" smallest keys after the cursor (plus one, to tell if there
+// is a next page) are kept while scanning, so a page costs O(n log limit).
+// Errors are *OpError for OpPage, with the cursor as key.
+func pageByKey[K comparable, V any](
+	ctx context.Context,
+	cursor string,
+	limit int,
+	scan func(visit func(k K, v V)),
+) (
+	items []Entry[K, V],
+	next string,
+	err error,
+) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpPage, Key: cursor, Err: err}
+		return
+	}
+
+	if !ordered[K]() {
+		err = fmt.Errorf("%w: paging needs keys which are numbers or strings", ErrImpl)
+		err = &OpError{Op: OpPage, Key: cursor, Err: err}
+		return
+	}
+
+	if limit < 1 {
+		err = fmt.Errorf("%w: page limit must be positive, got %d", ErrInvalid, limit)
+		err = &OpError{Op: OpPage, Key: cursor, Err: err}
+		return
+	}
+
+	after := func(K) bool { return true }
+	if cursor != "" {
+		var last K
+		if err = json.Unmarshal([]byte(cursor), &last); err != nil {
+			err = &OpError{Op: OpPage, Key: cursor, Err: fmt.Errorf("%w: bad cursor: %w", ErrInvalid, err)}
+			return
+		}
+
+		after = func(k K) bool { return keyCmp(k, last) > 0 }
+	}
+
+	h := make(pageHeap[K, V], 0, limit+1)
+	scan(func(k K, v V) {
+		switch {
+		case !after(k):
+		case len(h) <= limit:
+			heap.Push(&h, Entry[K, V]{k, v})
+		case keyCmp(k, h[0].Key) < 0:
+			h[0] = Entry[K, V]{k, v}
+			heap.Fix(&h, 0)
+		}
+	})
+
+	items = []Entry[K, V](h)
+	slices.SortFunc(items, func(a, b Entry[K, V]) int { return keyCmp(a.Key, b.Key) })
+	if len(items) <= limit {
+		return
+	}
+
+	items = items[:limit]
+	b, err := json.Marshal(items[limit-1].Key)
+	if err != nil {
+		items = nil
+		err = &OpError{Op: OpPage, Key: cursor, Err: err}
+		return
+	}
+
+	next = string(b)
+	return
+}
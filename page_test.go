@@ -0,0 +1,186 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Tests for PagerImpl.
+// -----------------------------------------------------------------------------
+
+func TestPagerImplWithNil(t *testing.T) {
+	_, _, err := PagerImpl[int, int]{}.Page(context.Background(), "", 1)
+	assertEq("err", true, errors.Is(err, ErrImpl), func(s string) { t.Fatal(s) })
+}
+
+// -----------------------------------------------------------------------------
+// Tests for the default Pager.
+// -----------------------------------------------------------------------------
+
+func TestNewPage(t *testing.T) {
+	for name, cnt := range map[string]Container[int, string]{
+		"New":        New[int, string](),
+		"NewSharded": NewSharded[int, string](4, nil),
+	} {
+		ctx := context.Background()
+		for _, k := range []int{5, -3, 10, 0, 7} {
+			cnt.Put(ctx, k, "v")
+		}
+
+		p := cnt.(Pager[int, string])
+		keys := [][]int{}
+		cursor := ""
+		for {
+			items, next, err := p.Page(ctx, cursor, 2)
+			assertEq(name+" err", *new(error), err, func(s string) { t.Fatal(s) })
+
+			page := []int{}
+			for _, item := range items {
+				page = append(page, item.Key)
+			}
+
+			keys = append(keys, page)
+			if next == "" {
+				break
+			}
+
+			cursor = next
+		}
+
+		want := [][]int{{-3, 0}, {5, 7}, {10}}
+		assertEq(name+" pages", want, keys, func(s string) { t.Fatal(s) })
+	}
+}
+
+func TestNewPageSeesLaterKeys(t *testing.T) {
+	cnt := New[string, int]()
+	ctx := context.Background()
+	cnt.Put(ctx, "a", 1)
+	cnt.Put(ctx, "c", 3)
+
+	p := cnt.(Pager[string, int])
+	items, next, _ := p.Page(ctx, "", 1)
+	assertEq("first", []Entry[string, int]{{"a", 1}}, items, func(s string) { t.Fatal(s) })
+
+	// Keys after the cursor are seen, keys before it are not.
+	cnt.Put(ctx, "b", 2)
+	cnt.Put(ctx, "0", 0)
+	items, next, _ = p.Page(ctx, next, 10)
+	assertEq("rest", []Entry[string, int]{{"b", 2}, {"c", 3}}, items, func(s string) { t.Fatal(s) })
+	assertEq("next", "", next, func(s string) { t.Fatal(s) })
+}
+
+func TestNewPageErrors(t *testing.T) {
+	ctx := context.Background()
+
+	p := New[int, int]().(Pager[int, int])
+	_, _, err := p.Page(ctx, "", 0)
+	assertEq("limit", true, errors.Is(err, ErrInvalid), func(s string) { t.Fatal(s) })
+
+	_, _, err = p.Page(ctx, "not json", 1)
+	assertEq("cursor", true, errors.Is(err, ErrInvalid), func(s string) { t.Fatal(s) })
+
+	type key struct{ A int }
+	_, _, err = New[key, int]().(Pager[key, int]).Page(ctx, "", 1)
+	assertEq("unordered", true, errors.Is(err, ErrImpl), func(s string) { t.Fatal(s) })
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, _, err = p.Page(canceled, "1", 1)
+	assertEq("canceled", true, errors.Is(err, context.Canceled), func(s string) { t.Fatal(s) })
+
+	opErr := &OpError{}
+	assertEq("op error", true, errors.As(err, &opErr), func(s string) { t.Fatal(s) })
+	assertEq("op", OpPage, opErr.Op, func(s string) { t.Fatal(s) })
+	assertEq("key", any("1"), opErr.Key, func(s string) { t.Fatal(s) })
+}
+
+func TestNewPageMany(t *testing.T) {
+	const n = 1000
+
+	for name, cnt := range map[string]Container[int, int]{
+		"new":     New[int, int](),
+		"sharded": NewSharded[int, int](4, nil),
+	} {
+		ctx := context.Background()
+		for i := 0; i < n; i++ {
+			// Not added in key order.
+			k := (i * 7919) % n
+			cnt.Put(ctx, k, k)
+		}
+
+		keys := []int{}
+		for e, err := range Paginate(ctx, cnt.(Pager[int, int]), 7) {
+			assertEq(name+" err", *new(error), err, func(s string) { t.Fatal(s) })
+			keys = append(keys, e.Key)
+		}
+
+		assertEq(name+" len", n, len(keys), func(s string) { t.Fatal(s) })
+		for i, k := range keys {
+			assertEq(name+" key", i, k, func(s string) { t.Fatal(s) })
+		}
+	}
+}
+
+func TestNewPageNamedKey(t *testing.T) {
+	type id uint8
+
+	cnt := New[id, int]()
+	ctx := context.Background()
+	cnt.Put(ctx, 200, 0)
+	cnt.Put(ctx, 3, 0)
+
+	items, _, err := cnt.(Pager[id, int]).Page(ctx, "", 10)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("items", []Entry[id, int]{{3, 0}, {200, 0}}, items, func(s string) { t.Fatal(s) })
+}
+
+// -----------------------------------------------------------------------------
+// Tests for Paginate.
+// -----------------------------------------------------------------------------
+
+func TestPaginate(t *testing.T) {
+	cnt := New[int, int]()
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		cnt.Put(ctx, i, i)
+	}
+
+	keys := []int{}
+	for item, err := range Paginate(ctx, cnt.(Pager[int, int]), 3) {
+		assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+		keys = append(keys, item.Key)
+	}
+
+	assertEq("keys", []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, keys, func(s string) { t.Fatal(s) })
+}
+
+func TestPaginateErr(t *testing.T) {
+	calls := 0
+	p := PagerImpl[int, int]{}
+	p.Impl = func(ctx context.Context, cursor string, limit int) ([]Entry[int, int], string, error) {
+		calls++
+		if cursor == "" {
+			return []Entry[int, int]{{1, 1}}, "1", nil
+		}
+
+		return nil, "", errFlaky
+	}
+
+	items, errs := 0, 0
+	for _, err := range Paginate(context.Background(), Pager[int, int](p), 1) {
+		if err != nil {
+			errs++
+			assertEq("err", true, errors.Is(err, errFlaky), func(s string) { t.Fatal(s) })
+			continue
+		}
+
+		items++
+	}
+
+	assertEq("items", 1, items, func(s string) { t.Fatal(s) })
+	assertEq("errs", 1, errs, func(s string) { t.Fatal(s) })
+	assertEq("calls", 2, calls, func(s string) { t.Fatal(s) })
+}
@@ -0,0 +1,45 @@
+package gontainer
+
+import (
+	"context"
+	"iter"
+)
+
+// All returns an iterator over the entries of "c", if it implements Ranger.
+// Otherwise, it returns ErrImpl.
+//
+// Note, the decorated containers of this package (e.g. from DecorateContainer)
+// do not implement Ranger, so range over the container which they decorate.
+func All[K comparable, V any](
+	ctx context.Context,
+	c Container[K, V],
+) (
+	seq iter.Seq2[K, V],
+	err error,
+) {
+	r, ok := c.(Ranger[K, V])
+	if !ok {
+		err = ErrImpl
+		return
+	}
+
+	return r.All(ctx), nil
+}
+
+// Keys returns an iterator over the keys of "c", if it implements Ranger.
+// Otherwise, it returns ErrImpl. See All.
+func Keys[K comparable, V any](
+	ctx context.Context,
+	c Container[K, V],
+) (
+	seq iter.Seq[K],
+	err error,
+) {
+	r, ok := c.(Ranger[K, V])
+	if !ok {
+		err = ErrImpl
+		return
+	}
+
+	return r.Keys(ctx), nil
+}
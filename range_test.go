@@ -0,0 +1,113 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"maps"
+	"slices"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Tests for RangerImpl.
+// -----------------------------------------------------------------------------
+
+func TestRangerImplWithNil(t *testing.T) {
+	r := RangerImpl[int, int]{}
+
+	n := 0
+	for range r.All(context.Background()) {
+		n++
+	}
+	for range r.Keys(context.Background()) {
+		n++
+	}
+
+	assertEq("n", 0, n, func(s string) { t.Fatal(s) })
+}
+
+func TestRangerImplKeysFromAll(t *testing.T) {
+	r := RangerImpl[int, int]{}
+	r.ImplAll = func(context.Context) iter.Seq2[int, int] {
+		return maps.All(map[int]int{1: 10, 2: 20})
+	}
+
+	keys := slices.Sorted(r.Keys(context.Background()))
+	assertEq("keys", []int{1, 2}, keys, func(s string) { t.Fatal(s) })
+}
+
+// -----------------------------------------------------------------------------
+// Tests for All and Keys.
+// -----------------------------------------------------------------------------
+
+func TestAllNew(t *testing.T) {
+	for name, cnt := range map[string]Container[int, int]{
+		"New":        New[int, int](),
+		"NewSharded": NewSharded[int, int](4, nil),
+	} {
+		ctx := context.Background()
+		for i := 0; i < 10; i++ {
+			cnt.Put(ctx, i, i*10)
+		}
+
+		seq, err := All(ctx, cnt)
+		assertEq(name+" err", *new(error), err, func(s string) { t.Fatal(s) })
+
+		want := map[int]int{}
+		for i := 0; i < 10; i++ {
+			want[i] = i * 10
+		}
+		assertEq(name+" all", want, maps.Collect(seq), func(s string) { t.Fatal(s) })
+
+		keys, err := Keys(ctx, cnt)
+		assertEq(name+" err", *new(error), err, func(s string) { t.Fatal(s) })
+		assertEq(name+" keys", slices.Sorted(maps.Keys(want)), slices.Sorted(keys), func(s string) { t.Fatal(s) })
+	}
+}
+
+func TestAllSnapshot(t *testing.T) {
+	cnt := New[int, int]()
+	ctx := context.Background()
+	cnt.Put(ctx, 1, 1)
+	cnt.Put(ctx, 2, 2)
+
+	seq, _ := All(ctx, cnt)
+
+	// Calling back into the container does not deadlock.
+	for k := range seq {
+		cnt.Del(ctx, k)
+		cnt.Put(ctx, k+10, k)
+	}
+
+	n, _ := cnt.Len(ctx)
+	assertEq("len", 2, n, func(s string) { t.Fatal(s) })
+}
+
+func TestAllCanceled(t *testing.T) {
+	cnt := New[int, int]()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for i := 0; i < 10; i++ {
+		cnt.Put(ctx, i, i)
+	}
+
+	seq, _ := All(ctx, cnt)
+
+	n := 0
+	for range seq {
+		n++
+		cancel()
+	}
+
+	assertEq("n", 1, n, func(s string) { t.Fatal(s) })
+}
+
+func TestAllUnsupported(t *testing.T) {
+	_, err := All[int, int](context.Background(), ContainerImpl[int, int]{})
+	assertEq("all", true, errors.Is(err, ErrImpl), func(s string) { t.Fatal(s) })
+
+	_, err = Keys[int, int](context.Background(), ContainerImpl[int, int]{})
+	assertEq("keys", true, errors.Is(err, ErrImpl), func(s string) { t.Fatal(s) })
+}
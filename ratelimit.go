@@ -0,0 +1,234 @@
+package gontainer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limit is the configuration of a token bucket.
+type Limit struct {
+	// Rate is how many tokens are added to the bucket per second. The bucket
+	// does not limit anything if zero or negative.
+	Rate float64
+	// Burst is the size of the bucket, i.e. how many calls can be made at once
+	// after it has filled up. Defaults to 1 if zero or negative.
+	Burst int
+}
+
+func (l Limit) burst() float64 {
+	return float64(max(l.Burst, 1))
+}
+
+// bucket is a token bucket, configured by a Limit. Buckets start out full.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// fill adds the tokens which accumulated since the last fill.
+func (b *bucket) fill(l Limit, now time.Time) {
+	if b.last.IsZero() {
+		b.tokens = l.burst()
+	}
+
+	b.tokens = min(l.burst(), b.tokens+now.Sub(b.last).Seconds()*l.Rate)
+	b.last = now
+}
+
+// RateLimitConfig is used to configure RateLimitDecorator. Reads are Get, Len,
+// Cap and Search. Writes are Put, Mod, Del, SearchUpdate and SearchDelete.
+type RateLimitConfig struct {
+	// Read is the global limit of reads.
+	Read Limit
+	// Write is the global limit of writes.
+	Write Limit
+	// KeyRead is the limit of reads per key. Only applies to Get.
+	KeyRead Limit
+	// KeyWrite is the limit of writes per key. Only applies to Put, Mod and
+	// Del.
+	KeyWrite Limit
+	// Wait makes calls block until they are allowed, or until their ctx is
+	// done. By default, calls which are not allowed fail fast instead.
+	Wait bool
+	// Clock is the source of time. Defaults to the wall clock if nil.
+	Clock Clock
+}
+
+// limiter is the state shared by all calls through a RateLimitDecorator.
+type limiter struct {
+	mu       sync.Mutex
+	cfg      RateLimitConfig
+	clock    Clock
+	read     bucket
+	write    bucket
+	keyRead  map[any]*bucket
+	keyWrite map[any]*bucket
+	pruneAt  int
+}
+
+// limiterPruneAt is the min number of per-key buckets before they are pruned.
+const limiterPruneAt = 1024
+
+// limited pairs a bucket with its limit.
+type limited struct {
+	b *bucket
+	l Limit
+}
+
+// buckets returns the buckets which apply to "op" on "key". Must be called
+// while locked.
+func (lim *limiter) buckets(op Op, key any) (bs []limited) {
+	global, perKey := lim.cfg.Read, lim.cfg.KeyRead
+	globalBucket, keyBuckets := &lim.read, lim.keyRead
+
+	switch op {
+	case OpPut, OpMod, OpDel, OpSearchUpdate, OpSearchDelete:
+		global, perKey = lim.cfg.Write, lim.cfg.KeyWrite
+		globalBucket, keyBuckets = &lim.write, lim.keyWrite
+	}
+
+	if global.Rate > 0 {
+		bs = append(bs, limited{globalBucket, global})
+	}
+
+	switch op {
+	case OpPut, OpGet, OpMod, OpDel:
+	default:
+		return
+	}
+
+	if perKey.Rate > 0 {
+		b, ok := keyBuckets[key]
+		if !ok {
+			b = &bucket{}
+			keyBuckets[key] = b
+		}
+
+		bs = append(bs, limited{b, perKey})
+	}
+
+	return
+}
+
+// prune drops per-key buckets which are full, since they are equivalent to
+// new buckets. Must be called while locked.
+func (lim *limiter) prune(now time.Time) {
+	if len(lim.keyRead)+len(lim.keyWrite) < lim.pruneAt {
+		return
+	}
+
+	for _, m := range []struct {
+		buckets map[any]*bucket
+		l       Limit
+	}{
+		{lim.keyRead, lim.cfg.KeyRead},
+		{lim.keyWrite, lim.cfg.KeyWrite},
+	} {
+		for key, b := range m.buckets {
+			if b.fill(m.l, now); b.tokens >= m.l.burst() {
+				delete(m.buckets, key)
+			}
+		}
+	}
+
+	lim.pruneAt = max(limiterPruneAt, 2*(len(lim.keyRead)+len(lim.keyWrite)))
+}
+
+// acquire takes a token from all buckets which apply to "op" on "key".
+func (lim *limiter) acquire(ctx context.Context, op Op, key any) (err error) {
+	lim.mu.Lock()
+
+	now := lim.clock.Now()
+	lim.prune(now)
+
+	bs := lim.buckets(op, key)
+	for _, b := range bs {
+		b.b.fill(b.l, now)
+	}
+
+	if !lim.cfg.Wait {
+		defer lim.mu.Unlock()
+		for _, b := range bs {
+			if b.b.tokens < 1 {
+				return ErrRateLimited
+			}
+		}
+
+		for _, b := range bs {
+			b.b.tokens--
+		}
+
+		return
+	}
+
+	// Reserve the tokens up front, and wait for the longest deficit.
+	wait := time.Duration(0)
+	for _, b := range bs {
+		if b.b.tokens--; b.b.tokens < 0 {
+			wait = max(wait, time.Duration(-b.b.tokens/b.l.Rate*float64(time.Second)))
+		}
+	}
+
+	refund := func() {
+		for _, b := range bs {
+			b.b.tokens++
+		}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && now.Add(wait).After(deadline) {
+		refund()
+		lim.mu.Unlock()
+		return ErrRateLimited
+	}
+
+	lim.mu.Unlock()
+
+	if wait <= 0 {
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+		lim.mu.Lock()
+		refund()
+		lim.mu.Unlock()
+		return ctx.Err()
+	case <-lim.clock.After(wait):
+		return
+	}
+}
+
+// RateLimitDecorator returns a Decorator which throttles calls with token
+// buckets: a global bucket for reads and one for writes, plus optional
+// buckets per key. Calls which are not allowed fail fast with ErrRateLimited,
+// unless RateLimitConfig.Wait is set, in which case they block until allowed.
+// Blocked calls return the ctx error if the ctx is done, and fail fast with
+// ErrRateLimited if the ctx deadline would pass before they are allowed.
+//
+// The state is shared by all calls through the returned Decorator, so apply
+// the same Decorator to all interfaces which are backed by the same store.
+func RateLimitDecorator(cfg RateLimitConfig) Decorator {
+	lim := &limiter{
+		cfg:      cfg,
+		clock:    clockOr(cfg.Clock),
+		keyRead:  make(map[any]*bucket),
+		keyWrite: make(map[any]*bucket),
+		pruneAt:  limiterPruneAt,
+	}
+
+	return func(
+		ctx context.Context,
+		op Op,
+		key any,
+		call func(ctx context.Context) error,
+	) (
+		err error,
+	) {
+		if err = lim.acquire(ctx, op, key); err != nil {
+			return
+		}
+
+		return call(ctx)
+	}
+}
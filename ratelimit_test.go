@@ -0,0 +1,148 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Tests for RateLimitDecorator.
+// -----------------------------------------------------------------------------
+
+func TestRateLimitDecoratorFailFast(t *testing.T) {
+	clock := newFakeClock()
+	cfg := RateLimitConfig{Clock: clock}
+	cfg.Read = Limit{Rate: 1, Burst: 2}
+	cfg.Write = Limit{Rate: 1}
+
+	cnt := DecorateContainer(New[int, int](), RateLimitDecorator(cfg))
+	ctx := context.Background()
+
+	err := cnt.Put(ctx, 1, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	err = cnt.Put(ctx, 2, 2)
+	assertEq("err", true, errors.Is(err, ErrRateLimited), func(s string) { t.Fatal(s) })
+
+	// Reads have their own bucket.
+	_, err = cnt.Get(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	_, err = cnt.Len(ctx)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	_, err = cnt.Cap(ctx)
+	assertEq("err", true, errors.Is(err, ErrRateLimited), func(s string) { t.Fatal(s) })
+
+	clock.Advance(time.Second)
+
+	err = cnt.Put(ctx, 2, 2)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	_, err = cnt.Cap(ctx)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+}
+
+func TestRateLimitDecoratorPerKey(t *testing.T) {
+	clock := newFakeClock()
+	cfg := RateLimitConfig{Clock: clock}
+	cfg.KeyWrite = Limit{Rate: 1}
+
+	cnt := DecorateContainer(New[int, int](), RateLimitDecorator(cfg))
+	ctx := context.Background()
+
+	err := cnt.Put(ctx, 1, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	err = cnt.Mod(ctx, 1, func(v int) int { return v })
+	assertEq("err", true, errors.Is(err, ErrRateLimited), func(s string) { t.Fatal(s) })
+
+	// Other keys and reads are not limited.
+	err = cnt.Put(ctx, 2, 2)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	_, err = cnt.Get(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+}
+
+func TestRateLimitDecoratorSearchers(t *testing.T) {
+	cfg := RateLimitConfig{Clock: newFakeClock()}
+	cfg.Read = Limit{Rate: 1}
+	cfg.Write = Limit{Rate: 1}
+	d := RateLimitDecorator(cfg)
+	ctx := context.Background()
+
+	s := DecorateSearcher[int, int](SearcherImpl[int, int]{}, d)
+	su := DecorateSearchUpdater[int, int, int](SearchUpdaterImpl[int, int, int]{}, d)
+	sd := DecorateSearchDeleter[int, int](SearchDeleterImpl[int, int]{}, d)
+
+	_, err := s.Search(ctx, 0)
+	assertEq("err", true, errors.Is(err, ErrImpl), func(s string) { t.Fatal(s) })
+	_, err = s.Search(ctx, 0)
+	assertEq("err", true, errors.Is(err, ErrRateLimited), func(s string) { t.Fatal(s) })
+
+	_, err = su.SearchUpdate(ctx, 0, 0)
+	assertEq("err", true, errors.Is(err, ErrImpl), func(s string) { t.Fatal(s) })
+	_, err = sd.SearchDelete(ctx, 0)
+	assertEq("err", true, errors.Is(err, ErrRateLimited), func(s string) { t.Fatal(s) })
+}
+
+func TestRateLimitDecoratorWait(t *testing.T) {
+	clock := newFakeClock()
+	cfg := RateLimitConfig{Clock: clock, Wait: true}
+	cfg.Write = Limit{Rate: 2}
+
+	cnt := DecorateContainer(New[int, int](), RateLimitDecorator(cfg))
+	ctx := context.Background()
+
+	cnt.Put(ctx, 1, 1)
+
+	errs := make(chan error)
+	go func() { errs <- cnt.Put(ctx, 2, 2) }()
+
+	// Blocked for half a second, until the next token.
+	clock.BlockUntil(1)
+	clock.Advance(time.Second / 2)
+
+	err := <-errs
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+}
+
+func TestRateLimitDecoratorWaitCanceled(t *testing.T) {
+	clock := newFakeClock()
+	cfg := RateLimitConfig{Clock: clock, Wait: true}
+	cfg.Write = Limit{Rate: 1}
+
+	cnt := DecorateContainer(New[int, int](), RateLimitDecorator(cfg))
+	cnt.Put(context.Background(), 1, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() { errs <- cnt.Put(ctx, 2, 2) }()
+
+	clock.BlockUntil(1)
+	cancel()
+
+	err := <-errs
+	assertEq("err", true, errors.Is(err, context.Canceled), func(s string) { t.Fatal(s) })
+
+	// The token reserved by the canceled call was refunded, so there is no
+	// need to wait for the next one.
+	clock.Advance(time.Second)
+	ctx, cancel = context.WithDeadline(context.Background(), clock.Now().Add(time.Second/2))
+	defer cancel()
+
+	err = cnt.Put(ctx, 3, 3)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+}
+
+func TestRateLimitDecoratorWaitDeadline(t *testing.T) {
+	clock := newFakeClock()
+	cfg := RateLimitConfig{Clock: clock, Wait: true}
+	cfg.Write = Limit{Rate: 1}
+
+	cnt := DecorateContainer(New[int, int](), RateLimitDecorator(cfg))
+	cnt.Put(context.Background(), 1, 1)
+
+	ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(time.Second/2))
+	defer cancel()
+
+	err := cnt.Put(ctx, 2, 2)
+	assertEq("err", true, errors.Is(err, ErrRateLimited), func(s string) { t.Fatal(s) })
+}
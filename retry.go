@@ -0,0 +1,132 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// transient is the default classifier of errors which are worth retrying (or
+// counting as failures of a remote store). Errors which are not transient are
+// caused by the caller rather than the store, like a missing key.
+//
+// A deadline error is transient, since it is usually the timeout of a single
+// attempt (e.g. from TimeoutDecorator). The decorators which use transient do
+// not ask it about calls where the ctx of the caller itself is done.
+func transient(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, context.Canceled):
+		return false
+	case errors.Is(err, ErrImpl), errors.Is(err, ErrOpen):
+		return false
+	case errors.Is(err, ErrReadOnly), errors.Is(err, ErrWriteOnly):
+		return false
+	case errors.Is(err, ErrInvalid):
+		return false
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrExists):
+		return false
+	case errors.Is(err, ErrConflict):
+		return false
+	}
+
+	return true
+}
+
+// RetryConfig is used to configure RetryDecorator.
+type RetryConfig struct {
+	// Attempts is the max number of attempts, including the first one.
+	// Defaults to 3 if zero or negative.
+	Attempts int
+	// Base is the delay before the first retry, it is doubled for each retry
+	// after that. Defaults to 100ms if zero or negative.
+	Base time.Duration
+	// Max caps the delay between attempts. No cap if zero or negative.
+	Max time.Duration
+	// Jitter is the fraction (0 to 1) of each delay which is randomized, such
+	// that a delay "d" becomes a random duration between d*(1-Jitter) and d.
+	Jitter float64
+	// Retryable decides which errors are retried. Defaults to retrying all
+	// errors except context.Canceled, ErrImpl, ErrOpen, ErrReadOnly,
+	// ErrWriteOnly, ErrInvalid, ErrNotFound, ErrExists and ErrConflict. So
+	// context.DeadlineExceeded is retried, as with a per-attempt timeout
+	// from TimeoutDecorator. Errors are never retried once the ctx of the
+	// caller is done.
+	Retryable func(err error) bool
+	// Clock is used to wait between attempts. Defaults to the wall clock.
+	Clock Clock
+	// Rand returns a random number in [0, 1) and is used for jitter. Defaults
+	// to rand.Float64 from math/rand/v2 if nil.
+	Rand func() float64
+}
+
+// delay returns the delay before retry number "n", starting at 0.
+func (cfg RetryConfig) delay(n int) time.Duration {
+	d := cfg.Base
+	for i := 0; i < n && (cfg.Max <= 0 || d < cfg.Max); i++ {
+		d *= 2
+	}
+
+	if cfg.Max > 0 && d > cfg.Max {
+		d = cfg.Max
+	}
+
+	if cfg.Jitter > 0 {
+		d -= time.Duration(float64(d) * min(cfg.Jitter, 1) * cfg.Rand())
+	}
+
+	return d
+}
+
+// RetryDecorator returns a Decorator which retries failed calls with an
+// exponential backoff. A call is not retried if its error is not retryable
+// (see RetryConfig.Retryable), or if the ctx deadline would pass before the
+// next attempt; the last error is then returned. If the ctx is done while
+// waiting, the last error is returned joined with the ctx error.
+//
+// Note, a retried Mod will call its callback once per attempt.
+func RetryDecorator(cfg RetryConfig) Decorator {
+	if cfg.Attempts < 1 {
+		cfg.Attempts = 3
+	}
+	if cfg.Base <= 0 {
+		cfg.Base = 100 * time.Millisecond
+	}
+	if cfg.Retryable == nil {
+		cfg.Retryable = transient
+	}
+	if cfg.Rand == nil {
+		cfg.Rand = rand.Float64
+	}
+
+	clock := clockOr(cfg.Clock)
+
+	return func(
+		ctx context.Context,
+		op Op,
+		key any,
+		call func(ctx context.Context) error,
+	) (
+		err error,
+	) {
+		for attempt := 0; ; attempt++ {
+			err = call(ctx)
+			if err == nil || attempt+1 >= cfg.Attempts || ctx.Err() != nil || !cfg.Retryable(err) {
+				return
+			}
+
+			d := cfg.delay(attempt)
+			if deadline, ok := ctx.Deadline(); ok && !clock.Now().Add(d).Before(deadline) {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return errors.Join(err, ctx.Err())
+			case <-clock.After(d):
+			}
+		}
+	}
+}
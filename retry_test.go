@@ -0,0 +1,193 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// instantClock is a Clock where After fires immediately, and records all the
+// durations it was called with.
+type instantClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	delays []time.Duration
+}
+
+func (c *instantClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *instantClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.delays = append(c.delays, d)
+	c.now = c.now.Add(d)
+
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// failingContainer returns a ContainerImpl where Put and Get fail with "err"
+// for the first "n" calls, and forwards to New after that.
+func failingContainer(n int, err error) (c ContainerImpl[int, int], calls *int) {
+	cnt := New[int, int]()
+	calls = new(int)
+
+	c.PutterImpl.Impl = func(ctx context.Context, k, v int) error {
+		if *calls++; *calls <= n {
+			return err
+		}
+
+		return cnt.Put(ctx, k, v)
+	}
+	c.GetterImpl.Impl = func(ctx context.Context, k int) (int, error) {
+		if *calls++; *calls <= n {
+			return 0, err
+		}
+
+		return cnt.Get(ctx, k)
+	}
+
+	return
+}
+
+var errFlaky = errors.New("flaky")
+
+// -----------------------------------------------------------------------------
+// Tests for RetryDecorator.
+// -----------------------------------------------------------------------------
+
+func TestRetryDecoratorSucceeds(t *testing.T) {
+	c, calls := failingContainer(2, errFlaky)
+	clock := &instantClock{now: time.Now()}
+
+	cfg := RetryConfig{Attempts: 3, Base: time.Second, Clock: clock}
+	cnt := DecorateContainer[int, int](c, RetryDecorator(cfg))
+
+	err := cnt.Put(context.Background(), 1, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("calls", 3, *calls, func(s string) { t.Fatal(s) })
+
+	want := []time.Duration{time.Second, 2 * time.Second}
+	assertEq("delays", want, clock.delays, func(s string) { t.Fatal(s) })
+}
+
+func TestRetryDecoratorExhausted(t *testing.T) {
+	c, calls := failingContainer(10, errFlaky)
+	clock := &instantClock{now: time.Now()}
+
+	cfg := RetryConfig{Attempts: 4, Base: time.Second, Max: 3 * time.Second, Clock: clock}
+	cnt := DecorateContainer[int, int](c, RetryDecorator(cfg))
+
+	err := cnt.Put(context.Background(), 1, 1)
+	assertEq("err", true, errors.Is(err, errFlaky), func(s string) { t.Fatal(s) })
+	assertEq("calls", 4, *calls, func(s string) { t.Fatal(s) })
+
+	want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}
+	assertEq("delays", want, clock.delays, func(s string) { t.Fatal(s) })
+}
+
+func TestRetryDecoratorNotRetryable(t *testing.T) {
+	clock := &instantClock{now: time.Now()}
+	cfg := RetryConfig{Attempts: 3, Clock: clock}
+	cnt := DecorateContainer(New[int, int](), RetryDecorator(cfg))
+
+	// A missing key is not retried by default.
+	_, err := cnt.Get(context.Background(), 1)
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+	assertEq("delays", 0, len(clock.delays), func(s string) { t.Fatal(s) })
+
+	// Custom classifier.
+	c, calls := failingContainer(10, errFlaky)
+	cfg.Retryable = func(err error) bool { return false }
+	cnt = DecorateContainer[int, int](c, RetryDecorator(cfg))
+
+	err = cnt.Put(context.Background(), 1, 1)
+	assertEq("err", true, errors.Is(err, errFlaky), func(s string) { t.Fatal(s) })
+	assertEq("calls", 1, *calls, func(s string) { t.Fatal(s) })
+}
+
+func TestRetryDecoratorJitter(t *testing.T) {
+	c, _ := failingContainer(2, errFlaky)
+	clock := &instantClock{now: time.Now()}
+
+	cfg := RetryConfig{Attempts: 3, Base: time.Second, Jitter: 0.5, Clock: clock}
+	cfg.Rand = func() float64 { return 0.5 }
+	cnt := DecorateContainer[int, int](c, RetryDecorator(cfg))
+
+	cnt.Put(context.Background(), 1, 1)
+
+	want := []time.Duration{750 * time.Millisecond, 1500 * time.Millisecond}
+	assertEq("delays", want, clock.delays, func(s string) { t.Fatal(s) })
+}
+
+func TestRetryDecoratorDeadline(t *testing.T) {
+	c, calls := failingContainer(10, errFlaky)
+	clock := &instantClock{now: time.Now()}
+
+	cfg := RetryConfig{Attempts: 5, Base: time.Second, Clock: clock}
+	cnt := DecorateContainer[int, int](c, RetryDecorator(cfg))
+
+	// Room for retries after 1s and 2s, but not the one after 4s more.
+	ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(5*time.Second))
+	defer cancel()
+
+	err := cnt.Put(ctx, 1, 1)
+	assertEq("err", true, errors.Is(err, errFlaky), func(s string) { t.Fatal(s) })
+	assertEq("calls", 3, *calls, func(s string) { t.Fatal(s) })
+}
+
+func TestRetryDecoratorCanceled(t *testing.T) {
+	c, calls := failingContainer(10, errFlaky)
+	clock := newFakeClock()
+
+	cfg := RetryConfig{Attempts: 5, Base: time.Second, Clock: clock}
+	cnt := DecorateContainer[int, int](c, RetryDecorator(cfg))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() { errs <- cnt.Put(ctx, 1, 1) }()
+
+	// Cancel while waiting for the first retry.
+	clock.BlockUntil(1)
+	cancel()
+
+	err := <-errs
+	assertEq("err", true, errors.Is(err, errFlaky), func(s string) { t.Fatal(s) })
+	assertEq("err", true, errors.Is(err, context.Canceled), func(s string) { t.Fatal(s) })
+	assertEq("calls", 1, *calls, func(s string) { t.Fatal(s) })
+}
+
+func TestRetryDecoratorTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	calls := atomic.Int32{}
+	g := GetterImpl[int, int]{}
+	g.Impl = func(context.Context, int) (int, error) {
+		// Hangs on the first attempt only.
+		if calls.Add(1) == 1 {
+			<-release
+		}
+
+		return 2, nil
+	}
+
+	d := Chain(
+		RetryDecorator(RetryConfig{Attempts: 3, Clock: &instantClock{}}),
+		TimeoutDecorator(TimeoutConfig{Default: 10 * time.Millisecond}),
+	)
+
+	v, err := DecorateGetter[int, int](g, d).Get(context.Background(), 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 2, v, func(s string) { t.Fatal(s) })
+}
@@ -0,0 +1,160 @@
+package gontainer
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"iter"
+)
+
+// shardWrap spreads keys over a fixed set of independently locked mapWrap
+// shards, so writers to different shards do not contend on the same lock.
+type shardWrap[K comparable, V any] struct {
+	shards []*mapWrap[K, V]
+	hash   func(K) uint64
+}
+
+// NewSharded returns an in-memory container which spreads keys over "n"
+// independently locked shards, using "hash" to pick the shard of a key. It is
+// intended for write-heavy concurrent use, where the single lock of New would
+// serialize callers. Semantics are otherwise the same as for New, and it
+// implements Ranger, Pager and Swapper as well.
+//
+// Notes:
+//   - "n" is clamped to 1 if it is smaller.
+//   - If "hash" is nil, keys are hashed by their fmt representation, which
+//     works for any key but is slow. Prefer passing a hash func for K.
+func NewSharded[K comparable, V any](n int, hash func(K) uint64) Container[K, V] {
+	if n < 1 {
+		n = 1
+	}
+	if hash == nil {
+		hash = hashFmt[K]
+	}
+
+	s := &shardWrap[K, V]{shards: make([]*mapWrap[K, V], n), hash: hash}
+	for i := range s.shards {
+		s.shards[i] = newMapWrap[K, V]()
+	}
+
+	return s
+}
+
+// hashFmt is the fallback hash func used by NewSharded.
+func hashFmt[K comparable](k K) uint64 {
+	h := fnv.New64a()
+	fmt.Fprint(h, k)
+	return h.Sum64()
+}
+
+func (s *shardWrap[K, V]) shard(k K) *mapWrap[K, V] {
+	return s.shards[s.hash(k)%uint64(len(s.shards))]
+}
+
+// Put implements Putter.
+func (s *shardWrap[K, V]) Put(ctx context.Context, k K, v V) (err error) {
+	return s.shard(k).Put(ctx, k, v)
+}
+
+// Get implements Getter.
+func (s *shardWrap[K, V]) Get(ctx context.Context, k K) (v V, err error) {
+	return s.shard(k).Get(ctx, k)
+}
+
+// Mod implements Modifier. Only the shard of "k" is locked during the call.
+func (s *shardWrap[K, V]) Mod(ctx context.Context, k K, f func(V) V) (err error) {
+	return s.shard(k).Mod(ctx, k, f)
+}
+
+// Del implements Deleter.
+func (s *shardWrap[K, V]) Del(ctx context.Context, k K) (v V, err error) {
+	return s.shard(k).Del(ctx, k)
+}
+
+// PutIfAbsent implements Swapper.
+func (s *shardWrap[K, V]) PutIfAbsent(ctx context.Context, k K, v V) (err error) {
+	return s.shard(k).PutIfAbsent(ctx, k, v)
+}
+
+// CompareAndSwap implements Swapper.
+func (s *shardWrap[K, V]) CompareAndSwap(ctx context.Context, k K, old, new V) (err error) {
+	return s.shard(k).CompareAndSwap(ctx, k, old, new)
+}
+
+// CompareAndDelete implements Swapper.
+func (s *shardWrap[K, V]) CompareAndDelete(ctx context.Context, k K, old V) (err error) {
+	return s.shard(k).CompareAndDelete(ctx, k, old)
+}
+
+// Len implements Container.Len. The shards are not locked together, so under
+// concurrent writes the result is a sum of per-shard snapshots.
+func (s *shardWrap[K, V]) Len(ctx context.Context) (n int, err error) {
+	for _, shard := range s.shards {
+		l, err := shard.Len(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		n += l
+	}
+
+	return
+}
+
+// Cap implements Container.Cap by summing the Cap of all shards.
+func (s *shardWrap[K, V]) Cap(ctx context.Context) (n int, err error) {
+	for _, shard := range s.shards {
+		c, err := shard.Cap(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		n += c
+	}
+
+	return
+}
+
+// All implements Ranger. Each shard is snapshot when iteration reaches it, so
+// the iteration is not a consistent snapshot of the whole container.
+func (s *shardWrap[K, V]) All(ctx context.Context) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, shard := range s.shards {
+			for k, v := range shard.All(ctx) {
+				if !yield(k, v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Keys implements Ranger, see shardWrap.All.
+func (s *shardWrap[K, V]) Keys(ctx context.Context) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for _, shard := range s.shards {
+			for k := range shard.Keys(ctx) {
+				if !yield(k) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Page implements Pager, see mapWrap.Page.
+func (s *shardWrap[K, V]) Page(
+	ctx context.Context,
+	cursor string,
+	limit int,
+) (
+	items []Entry[K, V],
+	next string,
+	err error,
+) {
+	return pageByKey(ctx, cursor, limit, func(visit func(k K, v V)) {
+		for _, shard := range s.shards {
+			shard.scan(visit)
+		}
+	})
+}
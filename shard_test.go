@@ -0,0 +1,136 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func hashInt(k int) uint64 { return uint64(k) }
+
+// -----------------------------------------------------------------------------
+// Tests for NewSharded.
+// -----------------------------------------------------------------------------
+
+func TestNewShardedCRUD(t *testing.T) {
+	cnt := NewSharded[int, int](4, hashInt)
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		err := cnt.Put(ctx, i, i*10)
+		assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	}
+
+	val, err := cnt.Get(ctx, 3)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 30, val, func(s string) { t.Fatal(s) })
+
+	err = cnt.Mod(ctx, 3, func(v int) int { return v + 1 })
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	val, err = cnt.Del(ctx, 3)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 31, val, func(s string) { t.Fatal(s) })
+
+	_, err = cnt.Get(ctx, 3)
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+}
+
+func TestNewShardedLenCap(t *testing.T) {
+	cnt := NewSharded[int, int](4, hashInt)
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		cnt.Put(ctx, i, i)
+	}
+
+	l, err := cnt.Len(ctx)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("len", 10, l, func(s string) { t.Fatal(s) })
+
+	c, err := cnt.Cap(ctx)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("cap", 20, c, func(s string) { t.Fatal(s) })
+}
+
+func TestNewShardedNilHash(t *testing.T) {
+	cnt := NewSharded[string, int](0, nil)
+	ctx := context.Background()
+
+	cnt.Put(ctx, "a", 1)
+	cnt.Put(ctx, "b", 2)
+
+	val, err := cnt.Get(ctx, "b")
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 2, val, func(s string) { t.Fatal(s) })
+}
+
+func TestNewShardedConcurrent(t *testing.T) {
+	cnt := NewSharded[int, int](8, hashInt)
+	ctx := context.Background()
+
+	const workers = 16
+	const iters = 1000
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iters; i++ {
+				cnt.Mod(ctx, i%32, func(v int) int { return v + 1 })
+				cnt.Get(ctx, i%32)
+				cnt.Len(ctx)
+				cnt.Cap(ctx)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	sum := 0
+	for i := 0; i < 32; i++ {
+		v, _ := cnt.Get(ctx, i)
+		sum += v
+	}
+
+	assertEq("sum", workers*iters, sum, func(s string) { t.Fatal(s) })
+}
+
+// -----------------------------------------------------------------------------
+// Benchmarks, NewSharded vs New.
+// -----------------------------------------------------------------------------
+
+// benchPut spreads b.N calls to Put over a fixed number of goroutines.
+func benchPut(b *testing.B, cnt Container[int, int], goroutines int) {
+	ctx := context.Background()
+	per := b.N/goroutines + 1
+
+	b.ResetTimer()
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < per; i++ {
+				cnt.Put(ctx, g*per+i, i)
+			}
+		}(g)
+	}
+
+	wg.Wait()
+}
+
+func BenchmarkPut(b *testing.B) {
+	for _, goroutines := range []int{1, 8, 64} {
+		b.Run(fmt.Sprintf("New/goroutines=%d", goroutines), func(b *testing.B) {
+			benchPut(b, New[int, int](), goroutines)
+		})
+		b.Run(fmt.Sprintf("NewSharded/goroutines=%d", goroutines), func(b *testing.B) {
+			benchPut(b, NewSharded[int, int](32, hashInt), goroutines)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package gontainer
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogConfig is used to configure SlogDecorator.
+type SlogConfig struct {
+	// Logger is where records are logged. Defaults to slog.Default() if nil.
+	Logger *slog.Logger
+	// Level is the level of records for calls which succeed. Defaults to
+	// slog.LevelDebug if nil.
+	Level slog.Leveler
+	// ErrLevel is the level of records for calls which fail. Defaults to
+	// slog.LevelError if nil.
+	ErrLevel slog.Leveler
+	// Redact is called with each key before it is logged, and the result is
+	// logged instead. Keys are logged as they are if nil.
+	Redact func(op Op, key any) any
+	// Clock is used to measure latency. Defaults to the wall clock if nil.
+	Clock Clock
+}
+
+// SlogDecorator returns a Decorator which logs each call with log/slog. A
+// record contains the operation, the key, the latency and the error (if any).
+// The error of the call is returned unchanged.
+func SlogDecorator(cfg SlogConfig) Decorator {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	level := cfg.Level
+	if level == nil {
+		level = slog.LevelDebug
+	}
+
+	errLevel := cfg.ErrLevel
+	if errLevel == nil {
+		errLevel = slog.LevelError
+	}
+
+	clock := clockOr(cfg.Clock)
+
+	return func(
+		ctx context.Context,
+		op Op,
+		key any,
+		call func(ctx context.Context) error,
+	) (
+		err error,
+	) {
+		start := clock.Now()
+		err = call(ctx)
+		latency := clock.Now().Sub(start)
+
+		lvl := level.Level()
+		if err != nil {
+			lvl = errLevel.Level()
+		}
+
+		if !logger.Enabled(ctx, lvl) {
+			return
+		}
+
+		if cfg.Redact != nil {
+			key = cfg.Redact(op, key)
+		}
+
+		attrs := []slog.Attr{
+			slog.String("op", string(op)),
+			slog.Any("key", key),
+			slog.Duration("latency", latency),
+		}
+		if err != nil {
+			attrs = append(attrs, slog.Any("err", err))
+		}
+
+		logger.LogAttrs(ctx, lvl, "gontainer: "+string(op), attrs...)
+		return
+	}
+}
@@ -0,0 +1,91 @@
+package gontainer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// slogRecords decodes the lines written by a slog.JSONHandler.
+func slogRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	records := []map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		record := map[string]any{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatal(err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// -----------------------------------------------------------------------------
+// Tests for SlogDecorator.
+// -----------------------------------------------------------------------------
+
+func TestSlogDecorator(t *testing.T) {
+	buf := &bytes.Buffer{}
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug}
+
+	cfg := SlogConfig{}
+	cfg.Logger = slog.New(slog.NewJSONHandler(buf, opts))
+	cfg.Redact = func(op Op, key any) any { return "redacted" }
+
+	cnt := DecorateContainer(New[int, int](), SlogDecorator(cfg))
+	ctx := context.Background()
+
+	cnt.Put(ctx, 1, 1)
+	_, getErr := cnt.Get(ctx, 2)
+	assertEq("err", true, errors.Is(getErr, ErrGet), func(s string) { t.Fatal(s) })
+
+	records := slogRecords(t, buf)
+	assertEq("records", 2, len(records), func(s string) { t.Fatal(s) })
+
+	assertEq("level", "DEBUG", records[0]["level"], func(s string) { t.Fatal(s) })
+	assertEq("op", "put", records[0]["op"], func(s string) { t.Fatal(s) })
+	assertEq("key", "redacted", records[0]["key"], func(s string) { t.Fatal(s) })
+	if _, ok := records[0]["err"]; ok {
+		t.Fatal("unexpected err in record")
+	}
+
+	assertEq("level", "ERROR", records[1]["level"], func(s string) { t.Fatal(s) })
+	assertEq("op", "get", records[1]["op"], func(s string) { t.Fatal(s) })
+	assertEq("err", getErr.Error(), records[1]["err"], func(s string) { t.Fatal(s) })
+
+	if _, ok := records[1]["latency"]; !ok {
+		t.Fatal("expected latency in record")
+	}
+}
+
+func TestSlogDecoratorLevels(t *testing.T) {
+	buf := &bytes.Buffer{}
+	opts := &slog.HandlerOptions{Level: slog.LevelInfo}
+
+	cfg := SlogConfig{}
+	cfg.Logger = slog.New(slog.NewJSONHandler(buf, opts))
+	cfg.ErrLevel = slog.LevelWarn
+
+	cnt := New[int, int]()
+	g := DecorateGetter[int, int](cnt, SlogDecorator(cfg))
+	ctx := context.Background()
+
+	// Successful calls are logged at debug by default, which is filtered.
+	cnt.Put(ctx, 1, 1)
+	g.Get(ctx, 1)
+	g.Get(ctx, 2)
+
+	records := slogRecords(t, buf)
+	assertEq("records", 1, len(records), func(s string) { t.Fatal(s) })
+	assertEq("level", "WARN", records[0]["level"], func(s string) { t.Fatal(s) })
+	assertEq("key", 2, records[0]["key"], func(s string) { t.Fatal(s) })
+}
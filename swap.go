@@ -0,0 +1,225 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// -----------------------------------------------------------------------------
+// Swapper.
+// -----------------------------------------------------------------------------
+
+// Swapper represents something which does conditional writes atomically. It is
+// optional, and not part of Container.
+//
+//   - PutIfAbsent stores "val" only if "key" is missing, and fails with
+//     ErrExists otherwise.
+//   - CompareAndSwap stores "new" only if the value of "key" is "old".
+//   - CompareAndDelete deletes "key" only if its value is "old".
+//
+// The compare operations fail with ErrNotFound if "key" is missing, and with
+// ErrConflict if the value is not "old". As with sync.Map, values are compared
+// with ==, which panics if the dynamic type of V is not comparable.
+type Swapper[K comparable, V any] interface {
+	PutIfAbsent(ctx context.Context, key K, val V) (err error)
+	CompareAndSwap(ctx context.Context, key K, old, new V) (err error)
+	CompareAndDelete(ctx context.Context, key K, old V) (err error)
+}
+
+// SwapperImpl lets you implement Swapper with functions. The calls are simply
+// forwarded to the internal functions "ImplPutIfAbsent", "ImplCompareAndSwap"
+// and "ImplCompareAndDelete".
+type SwapperImpl[K comparable, V any] struct {
+	ImplPutIfAbsent      func(ctx context.Context, key K, val V) (err error)
+	ImplCompareAndSwap   func(ctx context.Context, key K, old, new V) (err error)
+	ImplCompareAndDelete func(ctx context.Context, key K, old V) (err error)
+}
+
+// PutIfAbsent implements Swapper.PutIfAbsent by forwarding the call to the
+// internal "ImplPutIfAbsent".
+func (impl SwapperImpl[K, V]) PutIfAbsent(
+	ctx context.Context,
+	key K,
+	val V,
+) (
+	err error,
+) {
+	if impl.ImplPutIfAbsent == nil {
+		err = ErrImpl
+		return
+	}
+
+	return impl.ImplPutIfAbsent(ctx, key, val)
+}
+
+// CompareAndSwap implements Swapper.CompareAndSwap by forwarding the call to
+// the internal "ImplCompareAndSwap".
+func (impl SwapperImpl[K, V]) CompareAndSwap(
+	ctx context.Context,
+	key K,
+	old V,
+	new V,
+) (
+	err error,
+) {
+	if impl.ImplCompareAndSwap == nil {
+		err = ErrImpl
+		return
+	}
+
+	return impl.ImplCompareAndSwap(ctx, key, old, new)
+}
+
+// CompareAndDelete implements Swapper.CompareAndDelete by forwarding the call
+// to the internal "ImplCompareAndDelete".
+func (impl SwapperImpl[K, V]) CompareAndDelete(
+	ctx context.Context,
+	key K,
+	old V,
+) (
+	err error,
+) {
+	if impl.ImplCompareAndDelete == nil {
+		err = ErrImpl
+		return
+	}
+
+	return impl.ImplCompareAndDelete(ctx, key, old)
+}
+
+// equal compares values as Swapper does.
+func equal[V any](a, b V) bool {
+	return any(a) == any(b)
+}
+
+// -----------------------------------------------------------------------------
+// Mod-based fallback.
+// -----------------------------------------------------------------------------
+
+// keyLocks is a set of mutexes per key, which are dropped when unused. The
+// zero value is ready to use.
+type keyLocks[K comparable] struct {
+	mu sync.Mutex
+	m  map[K]*keyLock
+}
+
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock locks "k" and returns the func which unlocks it.
+func (l *keyLocks[K]) lock(k K) (unlock func()) {
+	l.mu.Lock()
+	if l.m == nil {
+		l.m = make(map[K]*keyLock)
+	}
+
+	kl, ok := l.m[k]
+	if !ok {
+		kl = &keyLock{}
+		l.m[k] = kl
+	}
+	kl.refs++
+	l.mu.Unlock()
+
+	kl.mu.Lock()
+	return func() {
+		kl.mu.Unlock()
+
+		l.mu.Lock()
+		if kl.refs--; kl.refs == 0 {
+			delete(l.m, k)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// modSwapper implements Swapper on top of Container.Get and Container.Mod.
+type modSwapper[K comparable, V any] struct {
+	c     Container[K, V]
+	locks keyLocks[K]
+}
+
+// SwapperFor returns a Swapper for "c". If "c" implements Swapper itself (as
+// New, NewSharded and NewLRU do), it is returned as is. Otherwise, the
+// conditional writes fall back to Get and Mod of "c": Get finds out if the key
+// is present, and Mod does the compare and write of present keys. Mod must be
+// atomic per key and report missing keys with an error which wraps ErrNotFound
+// (see gontainertest).
+//
+// Notes:
+//   - The conditional writes of the fallback are atomic with regard to each
+//     other, and CompareAndSwap is atomic with regard to Mod of "c".
+//   - Missing keys are never written, so the fallback does not add keys (or
+//     cause evictions) only to find out that they are missing.
+//   - A key which is deleted without the Swapper, after Get found it, makes
+//     CompareAndSwap fail with ErrNotFound. Mod of "c" may have added it back.
+//   - CompareAndDelete compares with Get and then deletes with Del, so a write
+//     in between which is not made through the Swapper is lost.
+func SwapperFor[K comparable, V any](c Container[K, V]) Swapper[K, V] {
+	if s, ok := c.(Swapper[K, V]); ok {
+		return s
+	}
+
+	return &modSwapper[K, V]{c: c}
+}
+
+// PutIfAbsent implements Swapper.
+func (s *modSwapper[K, V]) PutIfAbsent(ctx context.Context, key K, val V) (err error) {
+	defer s.locks.lock(key)()
+
+	_, err = s.c.Get(ctx, key)
+	switch {
+	case err == nil:
+		return &OpError{Op: OpPut, Key: key, Err: ErrExists}
+	case !errors.Is(err, ErrNotFound):
+		return reOpError(OpPut, key, err)
+	}
+
+	return s.c.Put(ctx, key, val)
+}
+
+// CompareAndSwap implements Swapper.
+func (s *modSwapper[K, V]) CompareAndSwap(ctx context.Context, key K, old, new V) (err error) {
+	defer s.locks.lock(key)()
+
+	if _, err = s.c.Get(ctx, key); err != nil {
+		return reOpError(OpMod, key, err)
+	}
+
+	match := false
+	err = s.c.Mod(ctx, key, func(cur V) V {
+		if match = equal(cur, old); match {
+			return new
+		}
+
+		return cur
+	})
+
+	switch {
+	case err != nil:
+		return
+	case !match:
+		return &OpError{Op: OpMod, Key: key, Err: ErrConflict}
+	}
+
+	return
+}
+
+// CompareAndDelete implements Swapper.
+func (s *modSwapper[K, V]) CompareAndDelete(ctx context.Context, key K, old V) (err error) {
+	defer s.locks.lock(key)()
+
+	cur, err := s.c.Get(ctx, key)
+	switch {
+	case err != nil:
+		return reOpError(OpDel, key, err)
+	case !equal(cur, old):
+		return &OpError{Op: OpDel, Key: key, Err: ErrConflict}
+	}
+
+	_, err = s.c.Del(ctx, key)
+	return
+}
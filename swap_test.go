@@ -0,0 +1,205 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Tests for SwapperImpl.
+// -----------------------------------------------------------------------------
+
+func TestSwapperImplWithNil(t *testing.T) {
+	s := SwapperImpl[int, int]{}
+	ctx := context.Background()
+
+	err := s.PutIfAbsent(ctx, 1, 1)
+	assertEq("put", true, errors.Is(err, ErrImpl), func(s string) { t.Fatal(s) })
+
+	err = s.CompareAndSwap(ctx, 1, 1, 2)
+	assertEq("cas", true, errors.Is(err, ErrImpl), func(s string) { t.Fatal(s) })
+
+	err = s.CompareAndDelete(ctx, 1, 1)
+	assertEq("cad", true, errors.Is(err, ErrImpl), func(s string) { t.Fatal(s) })
+}
+
+// -----------------------------------------------------------------------------
+// Tests for the native and fallback Swappers.
+// -----------------------------------------------------------------------------
+
+// swappers returns the Swappers under test, and the containers behind them.
+func swappers() map[string]struct {
+	c Container[int, int]
+	s Swapper[int, int]
+} {
+	native := New[int, int]()
+	sharded := NewSharded[int, int](4, nil)
+	lru := NewLRU[int, int](100, nil)
+
+	// The decorator hides the native Swapper of New.
+	fallback := DecorateContainer(New[int, int](), NameDecorator("fallback"))
+
+	return map[string]struct {
+		c Container[int, int]
+		s Swapper[int, int]
+	}{
+		"native":   {native, SwapperFor(native)},
+		"sharded":  {sharded, SwapperFor(sharded)},
+		"lru":      {lru, SwapperFor(lru)},
+		"fallback": {fallback, SwapperFor(fallback)},
+	}
+}
+
+func TestSwapperPutIfAbsent(t *testing.T) {
+	for name, tc := range swappers() {
+		ctx := context.Background()
+		fatal := func(s string) { t.Fatal(name + ": " + s) }
+
+		err := tc.s.PutIfAbsent(ctx, 1, 0)
+		assertEq("absent", *new(error), err, fatal)
+
+		// Present, even though the value is the zero value.
+		err = tc.s.PutIfAbsent(ctx, 1, 2)
+		assertEq("exists", true, errors.Is(err, ErrExists), fatal)
+		assertEq("exists op", true, errors.Is(err, ErrPut), fatal)
+
+		v, _ := tc.c.Get(ctx, 1)
+		assertEq("val", 0, v, fatal)
+	}
+}
+
+func TestSwapperCompareAndSwap(t *testing.T) {
+	for name, tc := range swappers() {
+		ctx := context.Background()
+		fatal := func(s string) { t.Fatal(name + ": " + s) }
+
+		err := tc.s.CompareAndSwap(ctx, 1, 0, 1)
+		assertEq("missing", true, errors.Is(err, ErrNotFound), fatal)
+
+		// Missing keys are not left behind by the fallback.
+		n, _ := tc.c.Len(ctx)
+		assertEq("len", 0, n, fatal)
+
+		tc.c.Put(ctx, 1, 1)
+		err = tc.s.CompareAndSwap(ctx, 1, 5, 2)
+		assertEq("conflict", true, errors.Is(err, ErrConflict), fatal)
+		assertEq("conflict op", true, errors.Is(err, ErrMod), fatal)
+
+		err = tc.s.CompareAndSwap(ctx, 1, 1, 2)
+		assertEq("swap", *new(error), err, fatal)
+
+		v, _ := tc.c.Get(ctx, 1)
+		assertEq("val", 2, v, fatal)
+	}
+}
+
+func TestSwapperCompareAndDelete(t *testing.T) {
+	for name, tc := range swappers() {
+		ctx := context.Background()
+		fatal := func(s string) { t.Fatal(name + ": " + s) }
+
+		err := tc.s.CompareAndDelete(ctx, 1, 0)
+		assertEq("missing", true, errors.Is(err, ErrNotFound), fatal)
+
+		tc.c.Put(ctx, 1, 1)
+		err = tc.s.CompareAndDelete(ctx, 1, 5)
+		assertEq("conflict", true, errors.Is(err, ErrConflict), fatal)
+		assertEq("conflict op", true, errors.Is(err, ErrDel), fatal)
+
+		err = tc.s.CompareAndDelete(ctx, 1, 1)
+		assertEq("delete", *new(error), err, fatal)
+
+		_, err = tc.c.Get(ctx, 1)
+		assertEq("deleted", true, errors.Is(err, ErrNotFound), fatal)
+	}
+}
+
+func TestSwapperConcurrentIncrement(t *testing.T) {
+	const workers = 8
+	const iters = 100
+
+	for name, tc := range swappers() {
+		ctx := context.Background()
+		tc.c.Put(ctx, 1, 0)
+
+		wg := sync.WaitGroup{}
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < iters; {
+					v, _ := tc.c.Get(ctx, 1)
+					if tc.s.CompareAndSwap(ctx, 1, v, v+1) == nil {
+						i++
+					}
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		v, _ := tc.c.Get(ctx, 1)
+		assertEq(name+" val", workers*iters, v, func(s string) { t.Fatal(s) })
+	}
+}
+
+func TestSwapperFallbackDoesNotWriteMissing(t *testing.T) {
+	ctx := context.Background()
+	evicted := []int{}
+	lru := NewLRU(2, func(k, _ int) { evicted = append(evicted, k) })
+	s := SwapperFor(DecorateContainer(lru, NameDecorator("lru")))
+
+	lru.Put(ctx, 1, 1)
+	lru.Put(ctx, 2, 2)
+
+	err := s.CompareAndSwap(ctx, 3, 0, 3)
+	assertEq("cas", true, errors.Is(err, ErrNotFound), func(s string) { t.Fatal(s) })
+
+	err = s.CompareAndDelete(ctx, 3, 0)
+	assertEq("cad", true, errors.Is(err, ErrNotFound), func(s string) { t.Fatal(s) })
+
+	for _, k := range []int{1, 2} {
+		v, err := lru.Get(ctx, k)
+		assertEq("get err", *new(error), err, func(s string) { t.Fatal(s) })
+		assertEq("get val", k, v, func(s string) { t.Fatal(s) })
+	}
+
+	assertEq("evicted", 0, len(evicted), func(s string) { t.Fatal(s) })
+}
+
+func TestSwapperNotComparablePanics(t *testing.T) {
+	defer func() {
+		assertEq("panic", true, recover() != nil, func(s string) { t.Fatal(s) })
+	}()
+
+	c := New[int, any]()
+	c.Put(context.Background(), 1, []int{1})
+	SwapperFor(c).CompareAndSwap(context.Background(), 1, []int{1}, nil)
+}
+
+func TestSwapperFallbackGetErrOp(t *testing.T) {
+	c := ContainerImpl[int, int]{}
+	c.GetterImpl.Impl = func(_ context.Context, k int) (int, error) {
+		return 0, &OpError{Op: OpGet, Key: k, Err: errFlaky}
+	}
+
+	s := SwapperFor[int, int](c)
+	ctx := context.Background()
+
+	// Failures of the Get made by the fallback are reported for the
+	// operation which was called.
+	for _, tc := range []struct {
+		op  error
+		err error
+	}{
+		{ErrPut, s.PutIfAbsent(ctx, 1, 1)},
+		{ErrMod, s.CompareAndSwap(ctx, 1, 0, 1)},
+		{ErrDel, s.CompareAndDelete(ctx, 1, 0)},
+	} {
+		assertEq("err", true, errors.Is(tc.err, errFlaky), func(s string) { t.Fatal(s) })
+		assertEq("op", true, errors.Is(tc.err, tc.op), func(s string) { t.Fatal(s) })
+		assertEq("get", false, errors.Is(tc.err, ErrGet), func(s string) { t.Fatal(s) })
+	}
+}
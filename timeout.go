@@ -0,0 +1,58 @@
+package gontainer
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TimeoutConfig is used to configure TimeoutDecorator.
+type TimeoutConfig struct {
+	// Default is the timeout of operations which are not in Ops. Zero or
+	// negative means no timeout.
+	Default time.Duration
+	// Ops sets the timeout per operation, and overrides Default. Zero or
+	// negative means no timeout for that operation.
+	Ops map[Op]time.Duration
+}
+
+// TimeoutDecorator returns a Decorator which enforces a deadline for each
+// call, see TimeoutConfig. Each call gets a ctx with the deadline. If the call
+// does not return in time (e.g. because the implementation ignores the ctx),
+// the Decorator returns without waiting for it, with an error which wraps the
+// ctx error (context.DeadlineExceeded on timeout).
+//
+// Note, a call which is given up on keeps running in its own goroutine until
+// the implementation returns, and the callback of Mod may still be called.
+func TimeoutDecorator(cfg TimeoutConfig) Decorator {
+	return func(
+		ctx context.Context,
+		op Op,
+		key any,
+		call func(ctx context.Context) error,
+	) (
+		err error,
+	) {
+		timeout, ok := cfg.Ops[op]
+		if !ok {
+			timeout = cfg.Default
+		}
+
+		if timeout <= 0 {
+			return call(ctx)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		errs := make(chan error, 1)
+		go func() { errs <- call(ctx) }()
+
+		select {
+		case err = <-errs:
+			return
+		case <-ctx.Done():
+			return fmt.Errorf("gontainer: %s timed out: %w", op, ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Tests for TimeoutDecorator.
+// -----------------------------------------------------------------------------
+
+func TestTimeoutDecoratorIgnoredCtx(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	c := ContainerImpl[int, int]{}
+	c.GetterImpl.Impl = func(context.Context, int) (int, error) {
+		<-block
+		return 1, nil
+	}
+
+	cfg := TimeoutConfig{Default: time.Millisecond}
+	cnt := DecorateContainer[int, int](c, TimeoutDecorator(cfg))
+
+	val, err := cnt.Get(context.Background(), 1)
+	assertEq("err", true, errors.Is(err, context.DeadlineExceeded), func(s string) { t.Fatal(s) })
+	assertEq("val", 0, val, func(s string) { t.Fatal(s) })
+}
+
+func TestTimeoutDecoratorPerOp(t *testing.T) {
+	deadlines := map[Op]bool{}
+
+	c := ContainerImpl[int, int]{}
+	c.PutterImpl.Impl = func(ctx context.Context, _, _ int) error {
+		_, deadlines[OpPut] = ctx.Deadline()
+		return nil
+	}
+	c.GetterImpl.Impl = func(ctx context.Context, _ int) (int, error) {
+		_, deadlines[OpGet] = ctx.Deadline()
+		return 1, nil
+	}
+
+	cfg := TimeoutConfig{Default: time.Minute}
+	cfg.Ops = map[Op]time.Duration{OpGet: 0}
+	cnt := DecorateContainer[int, int](c, TimeoutDecorator(cfg))
+
+	err := cnt.Put(context.Background(), 1, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	val, err := cnt.Get(context.Background(), 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 1, val, func(s string) { t.Fatal(s) })
+
+	want := map[Op]bool{OpPut: true, OpGet: false}
+	assertEq("deadlines", want, deadlines, func(s string) { t.Fatal(s) })
+}
+
+func TestTimeoutDecoratorSearcher(t *testing.T) {
+	s := SearcherImpl[int, int]{}
+	s.Impl = func(ctx context.Context, _ int) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}
+
+	cfg := TimeoutConfig{Ops: map[Op]time.Duration{OpSearch: time.Millisecond}}
+	ds := DecorateSearcher[int, int](s, TimeoutDecorator(cfg))
+
+	_, err := ds.Search(context.Background(), 0)
+	assertEq("err", true, errors.Is(err, context.DeadlineExceeded), func(s string) { t.Fatal(s) })
+}
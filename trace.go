@@ -0,0 +1,152 @@
+package gontainer
+
+import (
+	"context"
+	"maps"
+	"sync"
+)
+
+// Tracer starts spans. It is meant to be implemented as a thin adapter over a
+// tracing library, such as OpenTelemetry.
+type Tracer interface {
+	// Start starts a span as a child of any span in "ctx", and returns a ctx
+	// which carries the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced operation, started with Tracer.Start.
+type Span interface {
+	SetAttribute(key string, val any)
+	RecordError(err error)
+	End()
+}
+
+// TraceConfig is used to configure TraceDecorator.
+type TraceConfig struct {
+	// Tracer starts the spans. Nothing is traced if nil.
+	Tracer Tracer
+	// Redact is called with each key before it is recorded, and the result is
+	// recorded instead. Keys are recorded as they are if nil.
+	Redact func(op Op, key any) any
+}
+
+// TraceDecorator returns a Decorator which starts a span for each call, as a
+// child of any span in the incoming ctx. Spans are named "gontainer.<op>",
+// have the "gontainer.op" and "gontainer.key" attributes (the latter is not
+// set for Len and Cap), and record the error of the call (if any). The call
+// gets the ctx returned by the tracer, and its error is returned unchanged.
+func TraceDecorator(cfg TraceConfig) Decorator {
+	return func(
+		ctx context.Context,
+		op Op,
+		key any,
+		call func(ctx context.Context) error,
+	) (
+		err error,
+	) {
+		if cfg.Tracer == nil {
+			return call(ctx)
+		}
+
+		ctx, span := cfg.Tracer.Start(ctx, "gontainer."+string(op))
+		defer span.End()
+
+		span.SetAttribute("gontainer.op", string(op))
+		if key != nil {
+			if cfg.Redact != nil {
+				key = cfg.Redact(op, key)
+			}
+
+			span.SetAttribute("gontainer.key", key)
+		}
+
+		err = call(ctx)
+		if err != nil {
+			span.RecordError(err)
+		}
+
+		return
+	}
+}
+
+// -----------------------------------------------------------------------------
+// SpanRecorder.
+// -----------------------------------------------------------------------------
+
+// SpanData is a snapshot of a span recorded by SpanRecorder.
+type SpanData struct {
+	Name   string
+	Parent string
+	Attrs  map[string]any
+	Err    error
+	Ended  bool
+}
+
+// SpanRecorder is an in-memory Tracer which records all spans, mainly intended
+// for testing. The zero value is ready to use, and it is safe for concurrent
+// use.
+type SpanRecorder struct {
+	mu    sync.Mutex
+	spans []*memSpan
+}
+
+// memSpan implements Span for SpanRecorder.
+type memSpan struct {
+	r    *SpanRecorder
+	data SpanData
+}
+
+// memSpanKey is the context key of the current memSpan.
+type memSpanKey struct{}
+
+// Start implements Tracer.Start.
+func (r *SpanRecorder) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &memSpan{r: r, data: SpanData{Name: name, Attrs: map[string]any{}}}
+	if parent, ok := ctx.Value(memSpanKey{}).(*memSpan); ok {
+		span.data.Parent = parent.data.Name
+	}
+
+	r.mu.Lock()
+	r.spans = append(r.spans, span)
+	r.mu.Unlock()
+
+	return context.WithValue(ctx, memSpanKey{}, span), span
+}
+
+// Spans returns a snapshot of all recorded spans, in the order they started.
+func (r *SpanRecorder) Spans() []SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spans := make([]SpanData, len(r.spans))
+	for i, span := range r.spans {
+		spans[i] = span.data
+		spans[i].Attrs = maps.Clone(span.data.Attrs)
+	}
+
+	return spans
+}
+
+// SetAttribute implements Span.SetAttribute.
+func (s *memSpan) SetAttribute(key string, val any) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	s.data.Attrs[key] = val
+}
+
+// RecordError implements Span.RecordError.
+func (s *memSpan) RecordError(err error) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	s.data.Err = err
+}
+
+// End implements Span.End.
+func (s *memSpan) End() {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	s.data.Ended = true
+}
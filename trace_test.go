@@ -0,0 +1,86 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Tests for TraceDecorator.
+// -----------------------------------------------------------------------------
+
+func TestTraceDecoratorContainer(t *testing.T) {
+	r := &SpanRecorder{}
+	cnt := DecorateContainer(New[int, int](), TraceDecorator(TraceConfig{Tracer: r}))
+	ctx := context.Background()
+
+	cnt.Put(ctx, 1, 1)
+	cnt.Get(ctx, 2)
+	cnt.Mod(ctx, 1, func(v int) int { return v })
+	cnt.Del(ctx, 1)
+	cnt.Len(ctx)
+	cnt.Cap(ctx)
+
+	spans := r.Spans()
+	assertEq("spans", 6, len(spans), func(s string) { t.Fatal(s) })
+
+	names := []string{}
+	for _, span := range spans {
+		names = append(names, span.Name)
+		assertEq("ended", true, span.Ended, func(s string) { t.Fatal(s) })
+	}
+
+	want := []string{
+		"gontainer.put",
+		"gontainer.get",
+		"gontainer.mod",
+		"gontainer.del",
+		"gontainer.len",
+		"gontainer.cap",
+	}
+	assertEq("names", want, names, func(s string) { t.Fatal(s) })
+
+	assertEq("key", 1, spans[0].Attrs["gontainer.key"], func(s string) { t.Fatal(s) })
+	assertEq("op", "put", spans[0].Attrs["gontainer.op"], func(s string) { t.Fatal(s) })
+	assertEq("err", true, spans[0].Err == nil, func(s string) { t.Fatal(s) })
+
+	assertEq("err", true, errors.Is(spans[1].Err, ErrGet), func(s string) { t.Fatal(s) })
+
+	_, ok := spans[4].Attrs["gontainer.key"]
+	assertEq("has key", false, ok, func(s string) { t.Fatal(s) })
+}
+
+func TestTraceDecoratorParent(t *testing.T) {
+	r := &SpanRecorder{}
+	d := TraceDecorator(TraceConfig{Tracer: r})
+
+	// The inner searcher is traced with the ctx of the outer span.
+	inner := DecorateSearcher[int, int](SearcherImpl[int, int]{
+		Impl: func(context.Context, int) (int, error) { return 0, nil },
+	}, d)
+	outer := DecorateSearcher[int, int](inner, d)
+
+	ctx, root := r.Start(context.Background(), "root")
+	outer.Search(ctx, 1)
+	root.End()
+
+	spans := r.Spans()
+	assertEq("spans", 3, len(spans), func(s string) { t.Fatal(s) })
+	assertEq("parent", "root", spans[1].Parent, func(s string) { t.Fatal(s) })
+	assertEq("parent", "gontainer.search", spans[2].Parent, func(s string) { t.Fatal(s) })
+}
+
+func TestTraceDecoratorRedact(t *testing.T) {
+	r := &SpanRecorder{}
+	cfg := TraceConfig{Tracer: r}
+	cfg.Redact = func(op Op, key any) any { return "***" }
+
+	su := SearchUpdaterImpl[string, int, int]{}
+	DecorateSearchUpdater[string, int, int](su, TraceDecorator(cfg)).
+		SearchUpdate(context.Background(), "secret", 1)
+
+	spans := r.Spans()
+	assertEq("key", "***", spans[0].Attrs["gontainer.key"], func(s string) { t.Fatal(s) })
+	assertEq("err", true, errors.Is(spans[0].Err, ErrImpl), func(s string) { t.Fatal(s) })
+}
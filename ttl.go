@@ -0,0 +1,263 @@
+package gontainer
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ttlKey is the context key used by WithTTL.
+type ttlKey struct{}
+
+// WithTTL returns a copy of "ctx" which carries a time-to-live. When passed to
+// Put or Mod of a container returned by NewTTL, it overrides TTLConfig.TTL for
+// that call. A zero or negative "ttl" means that the entry does not expire.
+func WithTTL(ctx context.Context, ttl time.Duration) context.Context {
+	return context.WithValue(ctx, ttlKey{}, ttl)
+}
+
+// TTLConfig is used to configure NewTTL.
+type TTLConfig struct {
+	// TTL is the default time-to-live of entries. Zero or negative means that
+	// entries do not expire, unless specified per call with WithTTL.
+	TTL time.Duration
+	// Interval is how often expired entries are reaped in the background.
+	// Zero or negative means no background reaping, expired entries are then
+	// only removed lazily when accessed.
+	Interval time.Duration
+	// Clock is the source of time. Defaults to the wall clock if nil.
+	Clock Clock
+}
+
+// ttlWrap decorates a Container with expiring entries. Deadlines are kept
+// beside the wrapped container, and calls are serialized per key so that the
+// two are kept in sync. The deadlines have their own lock, which is never held
+// while calling into the wrapped container.
+type ttlWrap[K comparable, V any] struct {
+	locks     keyLocks[K]
+	mu        sync.Mutex
+	cnt       Container[K, V]
+	cfg       TTLConfig
+	clock     Clock
+	deadlines map[K]time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewTTL decorates "c" such that entries expire after a time-to-live, which
+// is either TTLConfig.TTL or set per call with WithTTL. Expired entries are
+// treated as missing; Get, Mod and Del return an error which wraps
+// ErrNotFound, and Len reaps them. If TTLConfig.Interval is set, a janitor
+// goroutine deletes expired entries from "c" in the background until "stop"
+// is called. It is safe to call "stop" more than once, and it returns when the
+// janitor has exited.
+//
+// Notes:
+//   - Mod keeps the deadline of an existing entry, unless WithTTL is used. If
+//     Mod adds a missing key (as New does), the entry gets the default TTL.
+//   - Only entries added through the returned container are tracked.
+func NewTTL[K comparable, V any](
+	c Container[K, V],
+	cfg TTLConfig,
+) (
+	cnt Container[K, V],
+	stop func(),
+) {
+	t := &ttlWrap[K, V]{
+		cnt:       c,
+		cfg:       cfg,
+		clock:     clockOr(cfg.Clock),
+		deadlines: make(map[K]time.Time),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	if cfg.Interval > 0 {
+		go t.janitor()
+	} else {
+		close(t.done)
+	}
+
+	stop = func() {
+		t.stopOnce.Do(func() { close(t.stop) })
+		<-t.done
+	}
+
+	return t, stop
+}
+
+func (t *ttlWrap[K, V]) janitor() {
+	defer close(t.done)
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-t.clock.After(t.cfg.Interval):
+			t.reap(context.Background())
+		}
+	}
+}
+
+// reap deletes all expired entries. Only the keys are collected while the
+// deadlines are locked, each key is then expired on its own. Keys which fail
+// to be deleted keep their deadline, so they are tried again later.
+func (t *ttlWrap[K, V]) reap(ctx context.Context) {
+	t.mu.Lock()
+	now := t.clock.Now()
+	expired := []K{}
+	for k, deadline := range t.deadlines {
+		if !now.Before(deadline) {
+			expired = append(expired, k)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, k := range expired {
+		unlock := t.locks.lock(k)
+		t.expire(ctx, k)
+		unlock()
+	}
+}
+
+// deadline returns the deadline of "k", if it has one.
+func (t *ttlWrap[K, V]) deadline(k K) (deadline time.Time, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	deadline, ok = t.deadlines[k]
+	return
+}
+
+// expire deletes "k" if it is expired, and reports whether it was. If the
+// delete fails, the deadline is kept so that it is tried again, and the error
+// is returned. Must be called while "k" is locked.
+func (t *ttlWrap[K, V]) expire(ctx context.Context, k K) (expired bool, err error) {
+	deadline, ok := t.deadline(k)
+	if !ok || t.clock.Now().Before(deadline) {
+		return
+	}
+
+	if _, err = t.cnt.Del(ctx, k); err != nil && !errors.Is(err, ErrNotFound) {
+		return
+	}
+
+	t.track(k, 0)
+	return true, nil
+}
+
+// track sets the deadline of "k" according to "ttl", or removes it if "ttl"
+// is zero or negative.
+func (t *ttlWrap[K, V]) track(k K, ttl time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if ttl <= 0 {
+		delete(t.deadlines, k)
+		return
+	}
+
+	t.deadlines[k] = t.clock.Now().Add(ttl)
+}
+
+// Put implements Putter.
+func (t *ttlWrap[K, V]) Put(ctx context.Context, k K, v V) (err error) {
+	defer t.locks.lock(k)()
+
+	err = t.cnt.Put(ctx, k, v)
+	if err != nil {
+		return
+	}
+
+	ttl, ok := ctx.Value(ttlKey{}).(time.Duration)
+	if !ok {
+		ttl = t.cfg.TTL
+	}
+
+	t.track(k, ttl)
+	return
+}
+
+// Get implements Getter.
+func (t *ttlWrap[K, V]) Get(ctx context.Context, k K) (v V, err error) {
+	defer t.locks.lock(k)()
+
+	expired, err := t.expire(ctx, k)
+	if err != nil || expired {
+		err = reOpError(OpGet, k, cmp.Or(err, ErrNotFound))
+		return
+	}
+
+	// The key may be gone without a call through here, e.g. if it was
+	// evicted, so its deadline is no longer needed.
+	v, err = t.cnt.Get(ctx, k)
+	if errors.Is(err, ErrNotFound) {
+		t.track(k, 0)
+	}
+
+	return
+}
+
+// Mod implements Modifier.
+func (t *ttlWrap[K, V]) Mod(ctx context.Context, k K, f func(V) V) (err error) {
+	defer t.locks.lock(k)()
+
+	if _, err = t.expire(ctx, k); err != nil {
+		return reOpError(OpMod, k, err)
+	}
+
+	_, tracked := t.deadline(k)
+
+	// A missing key was added by Mod, any other error means that nothing
+	// was written, so the deadline stays as it is.
+	err = t.cnt.Mod(ctx, k, f)
+	added := errors.Is(err, ErrNotFound)
+	if err != nil && !added {
+		return
+	}
+
+	if ttl, ok := ctx.Value(ttlKey{}).(time.Duration); ok {
+		t.track(k, ttl)
+		return
+	}
+
+	if !tracked && added {
+		t.track(k, t.cfg.TTL)
+	}
+
+	return
+}
+
+// Del implements Deleter.
+func (t *ttlWrap[K, V]) Del(ctx context.Context, k K) (v V, err error) {
+	defer t.locks.lock(k)()
+
+	expired, err := t.expire(ctx, k)
+	if err != nil || expired {
+		err = reOpError(OpDel, k, cmp.Or(err, ErrNotFound))
+		return
+	}
+
+	v, err = t.cnt.Del(ctx, k)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		t.track(k, 0)
+	}
+
+	return
+}
+
+// Len implements Container.Len. Expired entries are reaped first, so they are
+// not counted, while keys which are gone from the decorated container (e.g.
+// evicted by it) are not subtracted twice.
+func (t *ttlWrap[K, V]) Len(ctx context.Context) (n int, err error) {
+	t.reap(ctx)
+	return t.cnt.Len(ctx)
+}
+
+// Cap implements Container.Cap by forwarding to the decorated container.
+func (t *ttlWrap[K, V]) Cap(ctx context.Context) (n int, err error) {
+	return t.cnt.Cap(ctx)
+}
@@ -0,0 +1,213 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Tests for NewTTL.
+// -----------------------------------------------------------------------------
+
+func TestNewTTLGetExpired(t *testing.T) {
+	clock := newFakeClock()
+	cnt, stop := NewTTL(New[int, int](), TTLConfig{TTL: time.Second, Clock: clock})
+	defer stop()
+
+	ctx := context.Background()
+	cnt.Put(ctx, 1, 1)
+
+	val, err := cnt.Get(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 1, val, func(s string) { t.Fatal(s) })
+
+	clock.Advance(time.Second)
+
+	val, err = cnt.Get(ctx, 1)
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+	assertEq("val", 0, val, func(s string) { t.Fatal(s) })
+}
+
+func TestNewTTLWithTTL(t *testing.T) {
+	clock := newFakeClock()
+	cnt, stop := NewTTL(New[int, int](), TTLConfig{TTL: time.Second, Clock: clock})
+	defer stop()
+
+	ctx := context.Background()
+	cnt.Put(WithTTL(ctx, time.Minute), 1, 1)
+	cnt.Put(WithTTL(ctx, 0), 2, 2)
+
+	clock.Advance(time.Hour)
+
+	_, err := cnt.Get(ctx, 1)
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+
+	// Zero TTL means no expiry.
+	val, err := cnt.Get(ctx, 2)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 2, val, func(s string) { t.Fatal(s) })
+}
+
+func TestNewTTLLen(t *testing.T) {
+	clock := newFakeClock()
+	cnt, stop := NewTTL(New[int, int](), TTLConfig{TTL: time.Second, Clock: clock})
+	defer stop()
+
+	ctx := context.Background()
+	cnt.Put(ctx, 1, 1)
+	cnt.Put(WithTTL(ctx, time.Minute), 2, 2)
+
+	l, err := cnt.Len(ctx)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("len", 2, l, func(s string) { t.Fatal(s) })
+
+	clock.Advance(time.Second)
+
+	l, err = cnt.Len(ctx)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("len", 1, l, func(s string) { t.Fatal(s) })
+}
+
+func TestNewTTLLenGoneKeys(t *testing.T) {
+	clock := newFakeClock()
+	inner := NewLRU[int, int](1, nil)
+	cnt, stop := NewTTL(inner, TTLConfig{TTL: time.Second, Clock: clock})
+	defer stop()
+
+	// 1 is evicted by the LRU, and 2 is deleted without going through NewTTL.
+	ctx := context.Background()
+	cnt.Put(ctx, 1, 1)
+	cnt.Put(ctx, 2, 2)
+	inner.Del(ctx, 2)
+	clock.Advance(time.Second)
+
+	l, err := cnt.Len(ctx)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("len", 0, l, func(s string) { t.Fatal(s) })
+}
+
+func TestNewTTLModDel(t *testing.T) {
+	clock := newFakeClock()
+	cnt, stop := NewTTL(New[int, int](), TTLConfig{TTL: time.Second, Clock: clock})
+	defer stop()
+
+	ctx := context.Background()
+	cnt.Put(ctx, 1, 1)
+
+	// Mod keeps the deadline of existing entries.
+	clock.Advance(time.Second / 2)
+	err := cnt.Mod(ctx, 1, func(v int) int { return v + 1 })
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	clock.Advance(time.Second / 2)
+	_, err = cnt.Del(ctx, 1)
+	assertEq("err", true, errors.Is(err, ErrDel), func(s string) { t.Fatal(s) })
+
+	// Mod on an expired (thus missing) key adds it with the default TTL.
+	err = cnt.Mod(ctx, 1, func(v int) int { return v + 10 })
+	assertEq("err", true, errors.Is(err, ErrMod), func(s string) { t.Fatal(s) })
+
+	val, err := cnt.Del(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 10, val, func(s string) { t.Fatal(s) })
+}
+
+func TestNewTTLJanitor(t *testing.T) {
+	clock := newFakeClock()
+	inner := New[int, int]()
+	cfg := TTLConfig{TTL: time.Second, Interval: time.Minute, Clock: clock}
+	cnt, stop := NewTTL(inner, cfg)
+
+	ctx := context.Background()
+	cnt.Put(ctx, 1, 1)
+	cnt.Put(WithTTL(ctx, time.Hour), 2, 2)
+
+	// Fire the janitor, and wait until it is waiting for the next tick.
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	clock.BlockUntil(1)
+
+	stop()
+	stop()
+
+	l, err := inner.Len(ctx)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("len", 1, l, func(s string) { t.Fatal(s) })
+
+	_, err = inner.Get(ctx, 2)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewTTLReapLocksPerKey(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	clock := newFakeClock()
+	inner, blocked := blockingContainer(New[int, int](), 1, release)
+	cnt, stop := NewTTL[int, int](inner, TTLConfig{TTL: time.Second, Clock: clock})
+	defer stop()
+
+	ctx := context.Background()
+	cnt.Put(ctx, 1, 1)
+	cnt.Put(WithTTL(ctx, time.Minute), 2, 2)
+	clock.Advance(time.Second)
+
+	go cnt.(*ttlWrap[int, int]).reap(ctx)
+	<-blocked
+
+	// The reaper is stuck on deleting 1, which does not block other keys.
+	within(t, func() {
+		val, err := cnt.Get(ctx, 2)
+		assertEq("err", *new(error), err, func(s string) { t.Error(s) })
+		assertEq("val", 2, val, func(s string) { t.Error(s) })
+
+		err = cnt.Put(ctx, 3, 3)
+		assertEq("put err", *new(error), err, func(s string) { t.Error(s) })
+	})
+}
+
+func TestNewTTLExpireCanceled(t *testing.T) {
+	clock := newFakeClock()
+	inner := New[int, int]()
+	cnt, stop := NewTTL[int, int](inner, TTLConfig{TTL: time.Second, Clock: clock})
+	defer stop()
+
+	ctx := context.Background()
+	cnt.Put(ctx, 1, 1)
+	clock.Advance(time.Second)
+
+	// The expired entry can not be deleted with a canceled ctx.
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err := cnt.Get(canceled, 1)
+	assertEq("canceled", true, errors.Is(err, context.Canceled), func(s string) { t.Fatal(s) })
+	assertEq("canceled op", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+
+	// It is still expired, and deleted on the next try.
+	_, err = cnt.Get(ctx, 1)
+	assertEq("expired", true, errors.Is(err, ErrNotFound), func(s string) { t.Fatal(s) })
+
+	_, err = inner.Get(ctx, 1)
+	assertEq("deleted", true, errors.Is(err, ErrNotFound), func(s string) { t.Fatal(s) })
+}
+
+func TestNewTTLModFailedKeepsDeadline(t *testing.T) {
+	clock := newFakeClock()
+	cnt, stop := NewTTL(New[int, int](), TTLConfig{TTL: time.Second, Clock: clock})
+	defer stop()
+
+	ctx := context.Background()
+	cnt.Put(ctx, 1, 1)
+
+	canceled, cancel := context.WithCancel(WithTTL(ctx, 0))
+	cancel()
+	err := cnt.Mod(canceled, 1, func(v int) int { return v + 1 })
+	assertEq("canceled", true, errors.Is(err, context.Canceled), func(s string) { t.Fatal(s) })
+
+	// The failed Mod did not remove the deadline.
+	clock.Advance(time.Second)
+	_, err = cnt.Get(ctx, 1)
+	assertEq("expired", true, errors.Is(err, ErrNotFound), func(s string) { t.Fatal(s) })
+}
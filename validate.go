@@ -0,0 +1,104 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ValidateConfig is used to configure Validate. A validator may report several
+// problems at once by returning them joined with errors.Join.
+type ValidateConfig[K comparable, V any] struct {
+	// Key validates keys. Keys are not validated if nil.
+	Key func(key K) error
+	// Val validates values. Values are not validated if nil.
+	Val func(val V) error
+}
+
+func (cfg ValidateConfig[K, V]) key(key K) error {
+	if cfg.Key == nil {
+		return nil
+	}
+
+	return cfg.Key(key)
+}
+
+func (cfg ValidateConfig[K, V]) val(val V) error {
+	if cfg.Val == nil {
+		return nil
+	}
+
+	return cfg.Val(val)
+}
+
+// Validate decorates "c" such that keys and values are validated before they
+// are written. Put validates the key and value, while Mod validates the key
+// and the value returned by the callback. If validation fails, nothing is
+// written and the returned *OpError wraps ErrInvalid and the validator errors.
+//
+// Notes:
+//   - Mod of a missing key (checked with Get of "c") validates the result of
+//     the callback for the zero value, and is not forwarded to "c" if it is
+//     invalid. So a container which upserts (like New) does not store it.
+//   - Otherwise, Mod is forwarded to "c" even when the callback result is
+//     invalid, but the callback then returns the value it was given, leaving
+//     it unchanged. A key which is deleted between the Get and the Mod may
+//     then be added with the zero value.
+//   - The callback of Mod may be called twice for missing keys.
+func Validate[K comparable, V any](c Container[K, V], cfg ValidateConfig[K, V]) Container[K, V] {
+	return ContainerImpl[K, V]{
+		PutterImpl: PutterImpl[K, V]{
+			Impl: func(ctx context.Context, key K, val V) (err error) {
+				if err = errors.Join(cfg.key(key), cfg.val(val)); err != nil {
+					return &OpError{Op: OpPut, Key: key, Err: fmt.Errorf("%w: %w", ErrInvalid, err)}
+				}
+
+				return c.Put(ctx, key, val)
+			},
+		},
+		GetterImpl: GetterImpl[K, V]{Impl: c.Get},
+		ModifierImpl: ModifierImpl[K, V]{
+			Impl: func(ctx context.Context, key K, rcv func(v V) V) (err error) {
+				if err = cfg.key(key); err != nil {
+					return &OpError{Op: OpMod, Key: key, Err: fmt.Errorf("%w: %w", ErrInvalid, err)}
+				}
+
+				if rcv == nil {
+					return c.Mod(ctx, key, rcv)
+				}
+
+				// Mod of a missing key may add it, so it must not get that far
+				// with an invalid result.
+				if _, gerr := c.Get(ctx, key); errors.Is(gerr, ErrNotFound) {
+					if verr := cfg.val(rcv(*new(V))); verr != nil {
+						return &OpError{Op: OpMod, Key: key, Err: fmt.Errorf("%w: %w", ErrInvalid, verr)}
+					}
+				}
+
+				// The callback may be called after Mod returns if "c" gives
+				// up on it (e.g. on a timeout), hence the result guard.
+				invalid := &result[error]{}
+				err = c.Mod(ctx, key, func(v V) V {
+					set := invalid.attempt()
+					newV := rcv(v)
+					verr := cfg.val(newV)
+					set(verr)
+					if verr != nil {
+						return v
+					}
+
+					return newV
+				})
+
+				if verr := invalid.get(); verr != nil {
+					return &OpError{Op: OpMod, Key: key, Err: fmt.Errorf("%w: %w", ErrInvalid, verr)}
+				}
+
+				return
+			},
+		},
+		DeleterImpl: DeleterImpl[K, V]{Impl: c.Del},
+		ImplLen:     c.Len,
+		ImplCap:     c.Cap,
+	}
+}
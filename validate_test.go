@@ -0,0 +1,115 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+var errNegative = errors.New("negative")
+var errOdd = errors.New("odd")
+
+// validateInts rejects negative keys, and values which are negative or odd.
+var validateInts = ValidateConfig[int, int]{
+	Key: func(k int) error {
+		if k < 0 {
+			return errNegative
+		}
+
+		return nil
+	},
+	Val: func(v int) error {
+		errs := []error{}
+		if v < 0 {
+			errs = append(errs, errNegative)
+		}
+		if v%2 != 0 {
+			errs = append(errs, errOdd)
+		}
+
+		return errors.Join(errs...)
+	},
+}
+
+// -----------------------------------------------------------------------------
+// Tests for Validate.
+// -----------------------------------------------------------------------------
+
+func TestValidatePut(t *testing.T) {
+	inner := New[int, int]()
+	cnt := Validate(inner, validateInts)
+	ctx := context.Background()
+
+	err := cnt.Put(ctx, 1, 2)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	err = cnt.Put(ctx, 2, -1)
+	assertEq("err", true, errors.Is(err, ErrPut), func(s string) { t.Fatal(s) })
+	assertEq("err", true, errors.Is(err, ErrInvalid), func(s string) { t.Fatal(s) })
+	assertEq("err", true, errors.Is(err, errNegative), func(s string) { t.Fatal(s) })
+	assertEq("err", true, errors.Is(err, errOdd), func(s string) { t.Fatal(s) })
+
+	err = cnt.Put(ctx, -1, 2)
+	assertEq("err", true, errors.Is(err, errNegative), func(s string) { t.Fatal(s) })
+
+	n, _ := inner.Len(ctx)
+	assertEq("len", 1, n, func(s string) { t.Fatal(s) })
+}
+
+func TestValidateMod(t *testing.T) {
+	inner := New[int, int]()
+	cnt := Validate(inner, validateInts)
+	ctx := context.Background()
+
+	inner.Put(ctx, 1, 2)
+
+	err := cnt.Mod(ctx, 1, func(v int) int { return v + 2 })
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	err = cnt.Mod(ctx, 1, func(v int) int { return v + 1 })
+	assertEq("err", true, errors.Is(err, ErrMod), func(s string) { t.Fatal(s) })
+	assertEq("err", true, errors.Is(err, ErrInvalid), func(s string) { t.Fatal(s) })
+	assertEq("err", true, errors.Is(err, errOdd), func(s string) { t.Fatal(s) })
+
+	// The invalid result was not stored.
+	val, _ := inner.Get(ctx, 1)
+	assertEq("val", 4, val, func(s string) { t.Fatal(s) })
+
+	err = cnt.Mod(ctx, -1, func(v int) int { return v })
+	assertEq("err", true, errors.Is(err, errNegative), func(s string) { t.Fatal(s) })
+}
+
+func TestValidateModMissing(t *testing.T) {
+	inner := New[int, int]()
+	cnt := Validate(inner, validateInts)
+	ctx := context.Background()
+
+	err := cnt.Mod(ctx, 1, func(v int) int { return v + 1 })
+	assertEq("err", true, errors.Is(err, ErrInvalid), func(s string) { t.Fatal(s) })
+
+	// New upserts on Mod, but the key was not added.
+	_, err = inner.Get(ctx, 1)
+	assertEq("missing", true, errors.Is(err, ErrNotFound), func(s string) { t.Fatal(s) })
+
+	// A valid result is added as usual.
+	err = cnt.Mod(ctx, 1, func(v int) int { return v + 2 })
+	assertEq("err", true, errors.Is(err, ErrNotFound), func(s string) { t.Fatal(s) })
+
+	val, _ := inner.Get(ctx, 1)
+	assertEq("val", 2, val, func(s string) { t.Fatal(s) })
+}
+
+func TestValidateNilValidators(t *testing.T) {
+	cnt := Validate(New[int, int](), ValidateConfig[int, int]{})
+	ctx := context.Background()
+
+	err := cnt.Put(ctx, -1, -1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	err = cnt.Mod(ctx, -1, func(v int) int { return v - 1 })
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	val, err := cnt.Del(ctx, -1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", -2, val, func(s string) { t.Fatal(s) })
+}
@@ -0,0 +1,524 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// -----------------------------------------------------------------------------
+// Versioned.
+// -----------------------------------------------------------------------------
+
+// Versioned represents something which stores values with a version per key,
+// for optimistic concurrency control. Versions increase with each write of a
+// key, and the version 0 means that the key is missing.
+//
+//   - GetVersion returns the value of "key" along with its version.
+//   - PutVersion stores "val" only if the version of "key" is "expected", and
+//     returns the new version. Use 0 to store only if "key" is missing.
+//   - DelVersion deletes "key" only if its version is "expected".
+//
+// Writes fail with ErrConflict if the version is not "expected", and DelVersion
+// fails with ErrNotFound if "key" is missing.
+type Versioned[K comparable, V any] interface {
+	GetVersion(ctx context.Context, key K) (val V, version uint64, err error)
+	PutVersion(ctx context.Context, key K, val V, expected uint64) (version uint64, err error)
+	DelVersion(ctx context.Context, key K, expected uint64) (val V, err error)
+}
+
+// VersionedImpl lets you implement Versioned with functions. The calls are
+// simply forwarded to the internal functions "ImplGetVersion",
+// "ImplPutVersion" and "ImplDelVersion".
+type VersionedImpl[K comparable, V any] struct {
+	ImplGetVersion func(ctx context.Context, key K) (val V, version uint64, err error)
+	ImplPutVersion func(ctx context.Context, key K, val V, expected uint64) (version uint64, err error)
+	ImplDelVersion func(ctx context.Context, key K, expected uint64) (val V, err error)
+}
+
+// GetVersion implements Versioned.GetVersion by forwarding the call to the
+// internal "ImplGetVersion".
+func (impl VersionedImpl[K, V]) GetVersion(
+	ctx context.Context,
+	key K,
+) (
+	val V,
+	version uint64,
+	err error,
+) {
+	if impl.ImplGetVersion == nil {
+		err = ErrImpl
+		return
+	}
+
+	return impl.ImplGetVersion(ctx, key)
+}
+
+// PutVersion implements Versioned.PutVersion by forwarding the call to the
+// internal "ImplPutVersion".
+func (impl VersionedImpl[K, V]) PutVersion(
+	ctx context.Context,
+	key K,
+	val V,
+	expected uint64,
+) (
+	version uint64,
+	err error,
+) {
+	if impl.ImplPutVersion == nil {
+		err = ErrImpl
+		return
+	}
+
+	return impl.ImplPutVersion(ctx, key, val, expected)
+}
+
+// DelVersion implements Versioned.DelVersion by forwarding the call to the
+// internal "ImplDelVersion".
+func (impl VersionedImpl[K, V]) DelVersion(
+	ctx context.Context,
+	key K,
+	expected uint64,
+) (
+	val V,
+	err error,
+) {
+	if impl.ImplDelVersion == nil {
+		err = ErrImpl
+		return
+	}
+
+	return impl.ImplDelVersion(ctx, key, expected)
+}
+
+// VersionedContainer groups Container and Versioned.
+type VersionedContainer[K comparable, V any] interface {
+	Container[K, V]
+	Versioned[K, V]
+}
+
+// -----------------------------------------------------------------------------
+// In-memory implementation.
+// -----------------------------------------------------------------------------
+
+// versionedEntry is a value and its version.
+type versionedEntry[V any] struct {
+	val     V
+	version uint64
+}
+
+// versionedWrap is the in-memory VersionedContainer. Versions come from one
+// counter for all keys, so a key which is deleted and added again does not
+// reuse old versions.
+type versionedWrap[K comparable, V any] struct {
+	mu      sync.RWMutex
+	m       map[K]versionedEntry[V]
+	version uint64
+}
+
+// NewVersioned returns an in-memory VersionedContainer. The methods of
+// Container behave as for New, and write new versions unconditionally. It is
+// safe for concurrent use.
+func NewVersioned[K comparable, V any]() VersionedContainer[K, V] {
+	return &versionedWrap[K, V]{m: make(map[K]versionedEntry[V])}
+}
+
+// write stores "v" with a new version. Must be called while locked.
+func (w *versionedWrap[K, V]) write(k K, v V) uint64 {
+	w.version++
+	w.m[k] = versionedEntry[V]{v, w.version}
+	return w.version
+}
+
+// Put implements Putter.
+func (w *versionedWrap[K, V]) Put(ctx context.Context, k K, v V) (err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpPut, Key: k, Err: err}
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.write(k, v)
+	return
+}
+
+// Get implements Getter.
+func (w *versionedWrap[K, V]) Get(ctx context.Context, k K) (v V, err error) {
+	v, _, err = w.GetVersion(ctx, k)
+	return
+}
+
+// Mod implements Modifier. Note, will still do a write if "k" is not found,
+// but the returned error wraps ErrNotFound.
+func (w *versionedWrap[K, V]) Mod(ctx context.Context, k K, f func(V) V) (err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpMod, Key: k, Err: err}
+		return
+	}
+
+	if f == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	e, ok := w.m[k]
+	if !ok {
+		err = &OpError{Op: OpMod, Key: k, Err: ErrNotFound}
+	}
+
+	w.write(k, f(e.val))
+	return
+}
+
+// Del implements Deleter.
+func (w *versionedWrap[K, V]) Del(ctx context.Context, k K) (v V, err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpDel, Key: k, Err: err}
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	e, ok := w.m[k]
+	if !ok {
+		err = &OpError{Op: OpDel, Key: k, Err: ErrNotFound}
+		return
+	}
+
+	delete(w.m, k)
+	return e.val, nil
+}
+
+// Len implements Container.Len.
+func (w *versionedWrap[K, V]) Len(ctx context.Context) (n int, err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpLen, Err: err}
+		return
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	n = len(w.m)
+	return
+}
+
+// Cap implements Container.Cap, see mapWrap.Cap.
+func (w *versionedWrap[K, V]) Cap(ctx context.Context) (n int, err error) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpCap, Err: err}
+		return
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	n = len(w.m) * 2
+	return
+}
+
+// GetVersion implements Versioned.
+func (w *versionedWrap[K, V]) GetVersion(
+	ctx context.Context,
+	k K,
+) (
+	v V,
+	version uint64,
+	err error,
+) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpGet, Key: k, Err: err}
+		return
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	e, ok := w.m[k]
+	if !ok {
+		err = &OpError{Op: OpGet, Key: k, Err: ErrNotFound}
+		return
+	}
+
+	return e.val, e.version, nil
+}
+
+// PutVersion implements Versioned.
+func (w *versionedWrap[K, V]) PutVersion(
+	ctx context.Context,
+	k K,
+	v V,
+	expected uint64,
+) (
+	version uint64,
+	err error,
+) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpPut, Key: k, Err: err}
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.m[k].version != expected {
+		err = &OpError{Op: OpPut, Key: k, Err: ErrConflict}
+		return
+	}
+
+	return w.write(k, v), nil
+}
+
+// DelVersion implements Versioned.
+func (w *versionedWrap[K, V]) DelVersion(
+	ctx context.Context,
+	k K,
+	expected uint64,
+) (
+	v V,
+	err error,
+) {
+	if err = ctx.Err(); err != nil {
+		err = &OpError{Op: OpDel, Key: k, Err: err}
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	e, ok := w.m[k]
+	switch {
+	case !ok:
+		err = &OpError{Op: OpDel, Key: k, Err: ErrNotFound}
+	case e.version != expected:
+		err = &OpError{Op: OpDel, Key: k, Err: ErrConflict}
+	default:
+		delete(w.m, k)
+		v = e.val
+	}
+
+	return
+}
+
+// -----------------------------------------------------------------------------
+// Decorator for existing containers.
+// -----------------------------------------------------------------------------
+
+// VersionedVal is a value along with its version, as stored by Versioning.
+type VersionedVal[V any] struct {
+	Val     V
+	Version uint64
+}
+
+// VersioningConfig is used to configure Versioning.
+type VersioningConfig struct {
+	// Clock is the source of new versions. Defaults to the wall clock if nil.
+	Clock Clock
+}
+
+// versioning is the VersionedContainer returned by Versioning. Writes of the
+// same key through it are serialized by "locks", while the version checks of
+// PutVersion are done in Mod of "c".
+type versioning[K comparable, V any] struct {
+	c     Container[K, VersionedVal[V]]
+	locks keyLocks[K]
+	clock Clock
+}
+
+// Versioning returns a VersionedContainer which stores values along with their
+// versions in "c", such that an existing (e.g. remote) store can be used for
+// optimistic concurrency control. The methods of Container behave as for "c",
+// and write new versions unconditionally.
+//
+// "c" must implement Mod such that it is atomic per key, adds missing keys, and
+// reports them with an error which wraps ErrNotFound (as New does, and see
+// gontainertest). Writes then check and bump the version within Mod, so they
+// are atomic with regard to writers in other processes which use Versioning
+// over the same store.
+//
+// Notes:
+//   - A new version is the larger of the old version plus one and the clock
+//     (VersioningConfig.Clock) in nanoseconds. So a key which is deleted and added again does not
+//     in practice reuse versions, even though the old ones are gone.
+//   - DelVersion checks the version with Get and then deletes with Del. It is
+//     atomic with regard to other calls through the same VersionedContainer,
+//     but not to writers in other processes.
+//   - PutVersion checks with Get that a key is present before expecting a
+//     version other than 0 of it, so that Mod does not add it. A key which is
+//     deleted in between may still be added with the zero VersionedVal, which
+//     is then treated as missing.
+func Versioning[K comparable, V any](
+	c Container[K, VersionedVal[V]],
+	cfg VersioningConfig,
+) VersionedContainer[K, V] {
+	return &versioning[K, V]{c: c, clock: clockOr(cfg.Clock)}
+}
+
+// next returns the version which follows "version".
+func (w *versioning[K, V]) next(version uint64) uint64 {
+	return max(version+1, uint64(w.clock.Now().UnixNano()))
+}
+
+// Put implements Putter.
+func (w *versioning[K, V]) Put(ctx context.Context, k K, v V) (err error) {
+	defer w.locks.lock(k)()
+
+	err = w.c.Mod(ctx, k, func(cur VersionedVal[V]) VersionedVal[V] {
+		return VersionedVal[V]{v, w.next(cur.Version)}
+	})
+
+	if errors.Is(err, ErrNotFound) {
+		err = nil
+	}
+
+	return
+}
+
+// Get implements Getter.
+func (w *versioning[K, V]) Get(ctx context.Context, k K) (v V, err error) {
+	v, _, err = w.GetVersion(ctx, k)
+	return
+}
+
+// Mod implements Modifier. As with "c", the returned error wraps ErrNotFound
+// if "k" was missing.
+func (w *versioning[K, V]) Mod(ctx context.Context, k K, f func(V) V) (err error) {
+	if f == nil {
+		return w.c.Mod(ctx, k, nil)
+	}
+
+	defer w.locks.lock(k)()
+
+	return w.c.Mod(ctx, k, func(cur VersionedVal[V]) VersionedVal[V] {
+		return VersionedVal[V]{f(cur.Val), w.next(cur.Version)}
+	})
+}
+
+// Del implements Deleter.
+func (w *versioning[K, V]) Del(ctx context.Context, k K) (v V, err error) {
+	defer w.locks.lock(k)()
+
+	cur, err := w.c.Del(ctx, k)
+	if err == nil && cur.Version == 0 {
+		err = &OpError{Op: OpDel, Key: k, Err: ErrNotFound}
+		return
+	}
+
+	return cur.Val, err
+}
+
+// Len implements Container.Len by forwarding to "c".
+func (w *versioning[K, V]) Len(ctx context.Context) (n int, err error) {
+	return w.c.Len(ctx)
+}
+
+// Cap implements Container.Cap by forwarding to "c".
+func (w *versioning[K, V]) Cap(ctx context.Context) (n int, err error) {
+	return w.c.Cap(ctx)
+}
+
+// GetVersion implements Versioned.
+func (w *versioning[K, V]) GetVersion(
+	ctx context.Context,
+	k K,
+) (
+	v V,
+	version uint64,
+	err error,
+) {
+	cur, err := w.c.Get(ctx, k)
+	if err == nil && cur.Version == 0 {
+		err = &OpError{Op: OpGet, Key: k, Err: ErrNotFound}
+	}
+
+	if err != nil {
+		return
+	}
+
+	return cur.Val, cur.Version, nil
+}
+
+// PutVersion implements Versioned.
+func (w *versioning[K, V]) PutVersion(
+	ctx context.Context,
+	k K,
+	v V,
+	expected uint64,
+) (
+	version uint64,
+	err error,
+) {
+	defer w.locks.lock(k)()
+
+	if expected != 0 {
+		_, _, err = w.GetVersion(ctx, k)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			err = &OpError{Op: OpPut, Key: k, Err: ErrConflict}
+			return
+		case err != nil:
+			err = reOpError(OpPut, k, err)
+			return
+		}
+	}
+
+	// The callback may be called after Mod returns if "c" gives up on it
+	// (e.g. on a timeout), hence the result guard.
+	res := &result[uint64]{}
+	err = w.c.Mod(ctx, k, func(cur VersionedVal[V]) VersionedVal[V] {
+		set := res.attempt()
+		if cur.Version != expected {
+			set(0)
+			return cur
+		}
+
+		next := VersionedVal[V]{v, w.next(cur.Version)}
+		set(next.Version)
+		return next
+	})
+
+	// A missing key was either expected, or left unchanged by the callback.
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return
+	}
+
+	if version = res.get(); version == 0 {
+		err = &OpError{Op: OpPut, Key: k, Err: ErrConflict}
+		return
+	}
+
+	return version, nil
+}
+
+// DelVersion implements Versioned.
+func (w *versioning[K, V]) DelVersion(
+	ctx context.Context,
+	k K,
+	expected uint64,
+) (
+	v V,
+	err error,
+) {
+	defer w.locks.lock(k)()
+
+	_, version, err := w.GetVersion(ctx, k)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		err = &OpError{Op: OpDel, Key: k, Err: ErrNotFound}
+		return
+	case err != nil:
+		err = reOpError(OpDel, k, err)
+		return
+	case version != expected:
+		err = &OpError{Op: OpDel, Key: k, Err: ErrConflict}
+		return
+	}
+
+	cur, err := w.c.Del(ctx, k)
+	return cur.Val, err
+}
@@ -0,0 +1,321 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Tests for VersionedImpl.
+// -----------------------------------------------------------------------------
+
+func TestVersionedImplWithNil(t *testing.T) {
+	v := VersionedImpl[int, int]{}
+	ctx := context.Background()
+
+	_, _, err := v.GetVersion(ctx, 1)
+	assertEq("get", true, errors.Is(err, ErrImpl), func(s string) { t.Fatal(s) })
+
+	_, err = v.PutVersion(ctx, 1, 1, 0)
+	assertEq("put", true, errors.Is(err, ErrImpl), func(s string) { t.Fatal(s) })
+
+	_, err = v.DelVersion(ctx, 1, 0)
+	assertEq("del", true, errors.Is(err, ErrImpl), func(s string) { t.Fatal(s) })
+}
+
+// -----------------------------------------------------------------------------
+// Tests for NewVersioned and Versioning.
+// -----------------------------------------------------------------------------
+
+// tickClock implements Clock for tests. Each call of Now is a nanosecond after
+// the previous one, starting at the Unix epoch.
+type tickClock struct {
+	ticks atomic.Int64
+}
+
+func (c *tickClock) Now() time.Time {
+	return time.Unix(0, c.ticks.Add(1))
+}
+
+func (c *tickClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// versioneds returns the VersionedContainers under test. The one from
+// Versioning uses a tickClock, so its versions are predictable.
+func versioneds[K comparable, V any]() map[string]VersionedContainer[K, V] {
+	cfg := VersioningConfig{Clock: &tickClock{}}
+
+	return map[string]VersionedContainer[K, V]{
+		"native":     NewVersioned[K, V](),
+		"versioning": Versioning(New[K, VersionedVal[V]](), cfg),
+	}
+}
+
+func TestNewVersionedPutVersion(t *testing.T) {
+	for name, c := range versioneds[string, int]() {
+		fatal := func(s string) { t.Fatal(name + ": " + s) }
+		ctx := context.Background()
+
+		v1, err := c.PutVersion(ctx, "a", 1, 0)
+		assertEq("create err", *new(error), err, fatal)
+		assertEq("create version", true, v1 > 0, fatal)
+
+		// Already exists.
+		_, err = c.PutVersion(ctx, "a", 2, 0)
+		assertEq("create again", true, errors.Is(err, ErrConflict), fatal)
+		assertEq("create again op", true, errors.Is(err, ErrPut), fatal)
+
+		v2, err := c.PutVersion(ctx, "a", 2, v1)
+		assertEq("update err", *new(error), err, fatal)
+		assertEq("update version", true, v2 > v1, fatal)
+
+		// Stale version.
+		_, err = c.PutVersion(ctx, "a", 3, v1)
+		assertEq("stale", true, errors.Is(err, ErrConflict), fatal)
+
+		val, version, err := c.GetVersion(ctx, "a")
+		assertEq("get err", *new(error), err, fatal)
+		assertEq("get val", 2, val, fatal)
+		assertEq("get version", v2, version, fatal)
+
+		_, _, err = c.GetVersion(ctx, "b")
+		assertEq("get missing", true, errors.Is(err, ErrNotFound), fatal)
+	}
+}
+
+func TestNewVersionedDelVersion(t *testing.T) {
+	for name, c := range versioneds[string, int]() {
+		fatal := func(s string) { t.Fatal(name + ": " + s) }
+		ctx := context.Background()
+
+		_, err := c.DelVersion(ctx, "a", 0)
+		assertEq("missing", true, errors.Is(err, ErrNotFound), fatal)
+
+		v1, _ := c.PutVersion(ctx, "a", 1, 0)
+		c.Put(ctx, "a", 2)
+
+		_, err = c.DelVersion(ctx, "a", v1)
+		assertEq("stale", true, errors.Is(err, ErrConflict), fatal)
+		assertEq("stale op", true, errors.Is(err, ErrDel), fatal)
+
+		_, v2, _ := c.GetVersion(ctx, "a")
+		val, err := c.DelVersion(ctx, "a", v2)
+		assertEq("del err", *new(error), err, fatal)
+		assertEq("del val", 2, val, fatal)
+
+		// Versions are not reused when a key is added again.
+		v3, _ := c.PutVersion(ctx, "a", 3, 0)
+		assertEq("no reuse", true, v3 > v2, fatal)
+	}
+}
+
+func TestNewVersionedContainerBumps(t *testing.T) {
+	for name, c := range versioneds[int, int]() {
+		fatal := func(s string) { t.Fatal(name + ": " + s) }
+		ctx := context.Background()
+
+		c.Put(ctx, 1, 1)
+		_, v1, _ := c.GetVersion(ctx, 1)
+
+		c.Mod(ctx, 1, func(v int) int { return v + 1 })
+		val, v2, _ := c.GetVersion(ctx, 1)
+		assertEq("mod val", 2, val, fatal)
+		assertEq("mod version", true, v2 > v1, fatal)
+
+		_, err := c.PutVersion(ctx, 1, 3, v1)
+		assertEq("stale after mod", true, errors.Is(err, ErrConflict), fatal)
+	}
+}
+
+func TestNewVersionedConcurrentWriters(t *testing.T) {
+	for name, c := range versioneds[int, int]() {
+		fatal := func(s string) { t.Fatal(name + ": " + s) }
+		ctx := context.Background()
+
+		const workers = 16
+		const iters = 200
+
+		wg := sync.WaitGroup{}
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				last := uint64(0)
+				for i := 0; i < iters; {
+					val, version, err := c.GetVersion(ctx, 1)
+					if err != nil && !errors.Is(err, ErrNotFound) {
+						t.Error(err)
+						return
+					}
+
+					// A reader never sees a version go backwards.
+					if version < last {
+						t.Errorf("version went from %d to %d", last, version)
+						return
+					}
+					last = version
+
+					_, err = c.PutVersion(ctx, 1, val+1, version)
+					switch {
+					case err == nil:
+						i++
+					case errors.Is(err, ErrConflict):
+					default:
+						t.Error(err)
+						return
+					}
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		// No increment was lost to a concurrent writer.
+		val, _, err := c.GetVersion(ctx, 1)
+		assertEq("err", *new(error), err, fatal)
+		assertEq("val", workers*iters, val, fatal)
+	}
+}
+
+func TestNewVersionedConcurrentCreate(t *testing.T) {
+	for name, c := range versioneds[int, int]() {
+		fatal := func(s string) { t.Fatal(name + ": " + s) }
+		ctx := context.Background()
+
+		const workers = 16
+
+		var wins atomic.Int64
+		wg := sync.WaitGroup{}
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := c.PutVersion(ctx, 1, w, 0); err == nil {
+					wins.Add(1)
+				}
+			}()
+		}
+
+		wg.Wait()
+		assertEq("wins", int64(1), wins.Load(), fatal)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Tests for Versioning.
+// -----------------------------------------------------------------------------
+
+func TestVersioningSharedStore(t *testing.T) {
+	// Each decorator stands in for a separate process, so only Mod of the
+	// store keeps their writes apart.
+	store := New[int, VersionedVal[int]]()
+	ctx := context.Background()
+
+	const workers = 8
+	const iters = 100
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			c := Versioning(store, VersioningConfig{})
+			for i := 0; i < iters; {
+				val, version, err := c.GetVersion(ctx, 1)
+				if err != nil && !errors.Is(err, ErrNotFound) {
+					t.Error(err)
+					return
+				}
+
+				_, err = c.PutVersion(ctx, 1, val+1, version)
+				switch {
+				case err == nil:
+					i++
+				case errors.Is(err, ErrConflict):
+				default:
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	val, _, err := Versioning(store, VersioningConfig{}).GetVersion(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", workers*iters, val, func(s string) { t.Fatal(s) })
+}
+
+func TestVersioningMissingNotAdded(t *testing.T) {
+	store := New[int, VersionedVal[int]]()
+	c := Versioning(store, VersioningConfig{})
+	ctx := context.Background()
+
+	_, err := c.PutVersion(ctx, 1, 1, 5)
+	assertEq("conflict", true, errors.Is(err, ErrConflict), func(s string) { t.Fatal(s) })
+
+	n, _ := store.Len(ctx)
+	assertEq("len", 0, n, func(s string) { t.Fatal(s) })
+
+	// A zero VersionedVal is treated as missing.
+	store.Put(ctx, 1, VersionedVal[int]{})
+	_, _, err = c.GetVersion(ctx, 1)
+	assertEq("zero get", true, errors.Is(err, ErrNotFound), func(s string) { t.Fatal(s) })
+
+	_, err = c.PutVersion(ctx, 1, 1, 0)
+	assertEq("zero put", *new(error), err, func(s string) { t.Fatal(s) })
+}
+
+func TestVersioningGetErrOp(t *testing.T) {
+	store := ContainerImpl[int, VersionedVal[int]]{}
+	store.GetterImpl.Impl = func(_ context.Context, k int) (VersionedVal[int], error) {
+		return VersionedVal[int]{}, &OpError{Op: OpGet, Key: k, Err: errFlaky}
+	}
+
+	c := Versioning(store, VersioningConfig{})
+	ctx := context.Background()
+
+	// Failures of the Get made to check the version are reported for the
+	// operation which was called.
+	_, putErr := c.PutVersion(ctx, 1, 1, 1)
+	_, delErr := c.DelVersion(ctx, 1, 1)
+	for _, tc := range []struct {
+		op  error
+		err error
+	}{
+		{ErrPut, putErr},
+		{ErrDel, delErr},
+	} {
+		assertEq("err", true, errors.Is(tc.err, errFlaky), func(s string) { t.Fatal(s) })
+		assertEq("op", true, errors.Is(tc.err, tc.op), func(s string) { t.Fatal(s) })
+		assertEq("get", false, errors.Is(tc.err, ErrGet), func(s string) { t.Fatal(s) })
+	}
+}
+
+func TestVersioningClock(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 100)}
+	c := Versioning(New[int, VersionedVal[int]](), VersioningConfig{Clock: clock})
+	ctx := context.Background()
+
+	v1, err := c.PutVersion(ctx, 1, 1, 0)
+	assertEq("put err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("put version", uint64(100), v1, func(s string) { t.Fatal(s) })
+
+	// The clock stands still, so the old version is bumped by one.
+	v2, err := c.PutVersion(ctx, 1, 2, v1)
+	assertEq("bump err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("bump version", uint64(101), v2, func(s string) { t.Fatal(s) })
+
+	clock.Advance(time.Microsecond)
+	v3, err := c.PutVersion(ctx, 1, 3, v2)
+	assertEq("advance err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("advance version", uint64(1100), v3, func(s string) { t.Fatal(s) })
+}
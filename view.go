@@ -0,0 +1,58 @@
+package gontainer
+
+import "context"
+
+// ReadOnly returns a read-only view of "c". Get, Len and Cap are forwarded to
+// "c", while Put, Mod and Del fail with an *OpError which wraps ErrReadOnly.
+func ReadOnly[K comparable, V any](c Container[K, V]) Container[K, V] {
+	return ContainerImpl[K, V]{
+		PutterImpl: PutterImpl[K, V]{
+			Impl: func(ctx context.Context, key K, val V) (err error) {
+				return &OpError{Op: OpPut, Key: key, Err: ErrReadOnly}
+			},
+		},
+		GetterImpl: GetterImpl[K, V]{Impl: c.Get},
+		ModifierImpl: ModifierImpl[K, V]{
+			Impl: func(ctx context.Context, key K, rcv func(v V) V) (err error) {
+				return &OpError{Op: OpMod, Key: key, Err: ErrReadOnly}
+			},
+		},
+		DeleterImpl: DeleterImpl[K, V]{
+			Impl: func(ctx context.Context, key K) (val V, err error) {
+				err = &OpError{Op: OpDel, Key: key, Err: ErrReadOnly}
+				return
+			},
+		},
+		ImplLen: c.Len,
+		ImplCap: c.Cap,
+	}
+}
+
+// WriteOnly returns a write-only view (a sink) of "c". Put, Len and Cap are
+// forwarded to "c". Del is forwarded as well, but the deleted value is not
+// returned. Get and Mod (which would expose the stored value to the callback)
+// fail with an *OpError which wraps ErrWriteOnly.
+func WriteOnly[K comparable, V any](c Container[K, V]) Container[K, V] {
+	return ContainerImpl[K, V]{
+		PutterImpl: PutterImpl[K, V]{Impl: c.Put},
+		GetterImpl: GetterImpl[K, V]{
+			Impl: func(ctx context.Context, key K) (val V, err error) {
+				err = &OpError{Op: OpGet, Key: key, Err: ErrWriteOnly}
+				return
+			},
+		},
+		ModifierImpl: ModifierImpl[K, V]{
+			Impl: func(ctx context.Context, key K, rcv func(v V) V) (err error) {
+				return &OpError{Op: OpMod, Key: key, Err: ErrWriteOnly}
+			},
+		},
+		DeleterImpl: DeleterImpl[K, V]{
+			Impl: func(ctx context.Context, key K) (val V, err error) {
+				_, err = c.Del(ctx, key)
+				return
+			},
+		},
+		ImplLen: c.Len,
+		ImplCap: c.Cap,
+	}
+}
@@ -0,0 +1,87 @@
+package gontainer
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Tests for ReadOnly & WriteOnly.
+// -----------------------------------------------------------------------------
+
+func TestReadOnly(t *testing.T) {
+	inner := New[int, int]()
+	cnt := ReadOnly(inner)
+	ctx := context.Background()
+
+	inner.Put(ctx, 1, 1)
+
+	val, err := cnt.Get(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 1, val, func(s string) { t.Fatal(s) })
+
+	n, _ := cnt.Len(ctx)
+	assertEq("len", 1, n, func(s string) { t.Fatal(s) })
+
+	err = cnt.Put(ctx, 2, 2)
+	assertEq("err", true, errors.Is(err, ErrReadOnly), func(s string) { t.Fatal(s) })
+	assertEq("err", true, errors.Is(err, ErrPut), func(s string) { t.Fatal(s) })
+
+	err = cnt.Mod(ctx, 1, func(v int) int { return v + 1 })
+	assertEq("err", true, errors.Is(err, ErrReadOnly), func(s string) { t.Fatal(s) })
+	assertEq("err", true, errors.Is(err, ErrMod), func(s string) { t.Fatal(s) })
+
+	_, err = cnt.Del(ctx, 1)
+	assertEq("err", true, errors.Is(err, ErrReadOnly), func(s string) { t.Fatal(s) })
+	assertEq("err", true, errors.Is(err, ErrDel), func(s string) { t.Fatal(s) })
+
+	// Nothing changed.
+	val, _ = inner.Get(ctx, 1)
+	assertEq("val", 1, val, func(s string) { t.Fatal(s) })
+	n, _ = inner.Len(ctx)
+	assertEq("len", 1, n, func(s string) { t.Fatal(s) })
+}
+
+func TestWriteOnly(t *testing.T) {
+	inner := New[int, int]()
+	cnt := WriteOnly(inner)
+	ctx := context.Background()
+
+	err := cnt.Put(ctx, 1, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	_, err = cnt.Get(ctx, 1)
+	assertEq("err", true, errors.Is(err, ErrWriteOnly), func(s string) { t.Fatal(s) })
+	assertEq("err", true, errors.Is(err, ErrGet), func(s string) { t.Fatal(s) })
+
+	err = cnt.Mod(ctx, 1, func(v int) int { return v + 1 })
+	assertEq("err", true, errors.Is(err, ErrWriteOnly), func(s string) { t.Fatal(s) })
+
+	n, _ := cnt.Len(ctx)
+	assertEq("len", 1, n, func(s string) { t.Fatal(s) })
+
+	val, err := cnt.Del(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 0, val, func(s string) { t.Fatal(s) })
+
+	n, _ = inner.Len(ctx)
+	assertEq("len", 0, n, func(s string) { t.Fatal(s) })
+}
+
+func TestReadOnlyComposes(t *testing.T) {
+	r := &MemRecorder{}
+	d := MetricsDecorator(MetricsConfig{Recorder: r})
+
+	// Decorators see the errors of the view, and views accept decorated
+	// containers.
+	cnt := DecorateContainer(ReadOnly(DecorateContainer(New[int, int](), d)), d)
+	ctx := context.Background()
+
+	cnt.Put(ctx, 1, 1)
+	cnt.Get(ctx, 1)
+
+	assertEq("put calls", 1, r.Calls(OpPut), func(s string) { t.Fatal(s) })
+	assertEq("put errs", 1, r.Errors(OpPut, ErrReadOnly), func(s string) { t.Fatal(s) })
+	assertEq("get calls", 2, r.Calls(OpGet), func(s string) { t.Fatal(s) })
+}